@@ -14,8 +14,19 @@ import (
 
 // GlobalConfig represents the global ss-cli configuration
 type GlobalConfig struct {
-	PluginDir   string `yaml:"plugin_dir,omitempty"`
-	GitHubToken string `yaml:"github_token,omitempty"`
+	PluginDir     string       `yaml:"plugin_dir,omitempty"`
+	GitHubToken   string       `yaml:"github_token,omitempty"`
+	Hosts         []HostConfig `yaml:"hosts,omitempty"`
+	TrustedOwners []string     `yaml:"trusted_owners,omitempty"` // Owners whose templates may run commands
+}
+
+// HostConfig declares a self-hosted git server
+type HostConfig struct {
+	Name    string `yaml:"name,omitempty"`    // Site key used in sources (default: host of BaseURL)
+	Type    string `yaml:"type"`              // "gitlab", "gitea", "forgejo" or "github-enterprise"
+	BaseURL string `yaml:"base_url"`          // Web URL (e.g. "https://git.mycorp.net")
+	APIURL  string `yaml:"api_url,omitempty"` // API root (default derived from BaseURL)
+	Token   string `yaml:"token,omitempty"`   // Access token for private repos
 }
 
 // GitHubToken returns a token from config, gh CLI, or env (in that priority).
@@ -49,6 +60,25 @@ func GitHubToken() string {
 	return ""
 }
 
+// Hosts returns the self-hosted git servers declared in ~/.ss/config.yaml
+func Hosts() []HostConfig {
+	cfg, err := loadGlobalConfig()
+	if err != nil || cfg == nil {
+		return nil
+	}
+	return cfg.Hosts
+}
+
+// TrustedOwners returns the template owners declared trusted in
+// ~/.ss/config.yaml, as "site/owner" or "owner" (a GitHub owner)
+func TrustedOwners() []string {
+	cfg, err := loadGlobalConfig()
+	if err != nil || cfg == nil {
+		return nil
+	}
+	return cfg.TrustedOwners
+}
+
 // loadGlobalConfig loads the global config from ~/.ss/config.yaml
 func loadGlobalConfig() (*GlobalConfig, error) {
 	homeDir, err := os.UserHomeDir()
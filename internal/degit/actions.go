@@ -2,20 +2,42 @@ package degit
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strings"
 
+	"github.com/bmatcuk/doublestar/v4"
 	sdk "github.com/ssgohq/ss-plugin-sdk"
 )
 
 // Action represents a degit.json action
 type Action struct {
-	Action  string   `json:"action"`           // "clone" or "remove"
-	Src     string   `json:"src,omitempty"`    // Source repo for clone action
-	Files   []string `json:"files,omitempty"`  // Files to remove for remove action
-	Cache   bool     `json:"cache,omitempty"`  // Use cache for clone action
-	Verbose bool     `json:"verbose,omitempty"` // Verbose output for clone action
+	Action  string      `json:"action"`            // "clone", "remove", "rename"/"move", "copy", "replace", "patch", "prompt" or "run"
+	Src     string      `json:"src,omitempty"`     // Source repo for clone action
+	Files   []string    `json:"files,omitempty"`   // Files to remove for remove action, globs for replace action
+	Cache   bool        `json:"cache,omitempty"`   // Use cache for clone action
+	Verbose bool        `json:"verbose,omitempty"` // Verbose output for clone action
+	Dest    string      `json:"dest,omitempty"`    // Directory for clone action, relative to the destination
+	Subdir  string      `json:"subdir,omitempty"`  // Subdirectory of the clone action's source
+	Include []string    `json:"include,omitempty"` // Doublestar globs of files the clone action extracts
+	Exclude []string    `json:"exclude,omitempty"` // Doublestar globs of files the clone action skips
+	Name    string      `json:"name,omitempty"`    // Variable set by prompt action
+	Type    string      `json:"type,omitempty"`    // Prompt type: text, select, confirm or multiselect
+	Message string      `json:"message,omitempty"` // Question asked by prompt action
+	Choices []string    `json:"choices,omitempty"` // Choices for select and multiselect prompts
+	Default interface{} `json:"default,omitempty"` // Default answer for prompt action
+	From    string      `json:"from,omitempty"`    // Path or glob to rename/copy
+	To      string      `json:"to,omitempty"`      // Target path for rename/copy (a directory for globs)
+	Search  string      `json:"search,omitempty"`  // Text (or regex) to find for replace action
+	Replace string      `json:"replace,omitempty"` // Replacement for replace action ($1 expands regex groups)
+	Regex   bool        `json:"regex,omitempty"`   // Treat search as a regular expression
+	Patch   string      `json:"patch,omitempty"`   // Unified diff file applied by patch action
+	Command string      `json:"command,omitempty"` // Shell command for run action
+	If      string      `json:"if,omitempty"`      // Condition on variables; the action is skipped when false
 }
 
 // UnmarshalJSON implements custom unmarshaling to handle both string and array for files
@@ -49,29 +71,6 @@ func (a *Action) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// LoadActions loads actions from degit.json in the destination directory
-func LoadActions(destDir string) ([]Action, error) {
-	actionsPath := filepath.Join(destDir, "degit.json")
-
-	data, err := os.ReadFile(actionsPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // No actions file
-		}
-		return nil, err
-	}
-
-	var actions []Action
-	if err := json.Unmarshal(data, &actions); err != nil {
-		return nil, fmt.Errorf("failed to parse degit.json: %w", err)
-	}
-
-	// Remove the degit.json file after loading
-	_ = os.Remove(actionsPath)
-
-	return actions, nil
-}
-
 // ExecuteActions executes a list of actions
 func ExecuteActions(actions []Action, destDir string, degitInst *Degit) error {
 	if len(actions) == 0 {
@@ -79,6 +78,22 @@ func ExecuteActions(actions []Action, destDir string, degitInst *Degit) error {
 	}
 
 	for i, action := range actions {
+		if action.If != "" {
+			ok, err := evalCondition(action.If, degitInst.vars)
+			if err != nil {
+				return fmt.Errorf("action %d (%s): %w", i, action.Action, err)
+			}
+			if !ok {
+				if degitInst.options.Verbose {
+					sdk.Info(fmt.Sprintf("Skipping action %d (%s): %s is false", i, action.Action, action.If))
+				}
+				continue
+			}
+		}
+
+		// Answers of earlier prompts can be used in later actions
+		action = action.interpolate(newRenderer(degitInst.vars))
+
 		switch action.Action {
 		case "clone":
 			if err := executeCloneAction(action, destDir, degitInst); err != nil {
@@ -90,6 +105,31 @@ func ExecuteActions(actions []Action, destDir string, degitInst *Degit) error {
 				return fmt.Errorf("action %d (remove): %w", i, err)
 			}
 
+		case "rename", "move", "copy":
+			if err := executeMoveAction(action, destDir); err != nil {
+				return fmt.Errorf("action %d (%s): %w", i, action.Action, err)
+			}
+
+		case "replace":
+			if err := executeReplaceAction(action, destDir); err != nil {
+				return fmt.Errorf("action %d (replace): %w", i, err)
+			}
+
+		case "patch":
+			if err := executePatchAction(action, destDir); err != nil {
+				return fmt.Errorf("action %d (patch): %w", i, err)
+			}
+
+		case "prompt":
+			if err := executePromptAction(action, destDir, degitInst); err != nil {
+				return fmt.Errorf("action %d (prompt): %w", i, err)
+			}
+
+		case "run":
+			if err := executeRunAction(action, degitInst); err != nil {
+				return fmt.Errorf("action %d (run): %w", i, err)
+			}
+
 		default:
 			sdk.Warning(fmt.Sprintf("Unknown action: %s", action.Action))
 		}
@@ -98,31 +138,95 @@ func ExecuteActions(actions []Action, destDir string, degitInst *Degit) error {
 	return nil
 }
 
-// executeCloneAction executes a clone action (clones another repo into the same destination)
+// interpolate returns a copy of the action with variables substituted in
+// its source and paths
+func (a Action) interpolate(r *renderer) Action {
+	a.Src = r.render(a.Src)
+	a.Dest = r.render(a.Dest)
+	a.Subdir = r.render(a.Subdir)
+	a.From = r.render(a.From)
+	a.To = r.render(a.To)
+	a.Search = r.render(a.Search)
+	a.Replace = r.render(a.Replace)
+	a.Patch = r.render(a.Patch)
+	a.Command = r.render(a.Command)
+	if len(a.Files) > 0 {
+		files := make([]string, len(a.Files))
+		for i, file := range a.Files {
+			files[i] = r.render(file)
+		}
+		a.Files = files
+	}
+	return a
+}
+
+// executeCloneAction executes a clone action (clones another repo into the
+// destination, or a directory below it)
 func executeCloneAction(action Action, destDir string, degitInst *Degit) error {
 	if action.Src == "" {
 		return fmt.Errorf("clone action requires 'src' field")
 	}
 
-	sdk.Info(fmt.Sprintf("Cloning additional source: %s", action.Src))
+	// Security check: only local templates may clone local paths, so a
+	// remote manifest cannot copy files from the user's machine
+	if isLocalSource(action.Src) && (degitInst.source == nil || !degitInst.source.IsLocal()) {
+		return fmt.Errorf("clone action cannot use local source %s in a remote template", action.Src)
+	}
 
 	// Parse the source
 	src, err := ParseSource(action.Src)
 	if err != nil {
 		return err
 	}
+	if action.Subdir != "" {
+		src.Subdir = path.Join("/", src.Subdir, action.Subdir)
+	}
+
+	// Security check: prevent path traversal
+	target := filepath.Join(destDir, action.Dest)
+	if !hasPrefix(target, destDir) {
+		sdk.Warning(fmt.Sprintf("Skipping path traversal attempt: %s", action.Dest))
+		return nil
+	}
+	dir := path.Join(degitInst.dir, filepath.ToSlash(filepath.Clean(action.Dest)))
+
+	// Guard against cycles and runaway nesting; identical sources are
+	// cloned once
+	ok, err := degitInst.checkNested(src, dir, action.Include, action.Exclude)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		sdk.Info(fmt.Sprintf("Skipping %s (already cloned)", action.Src))
+		return nil
+	}
 
 	// Create a new degit instance for the nested clone
 	nestedDegit := New(Options{
-		Force:   true, // Force for nested clones
-		Cache:   action.Cache,
-		Verbose: action.Verbose,
-		Token:   degitInst.options.Token,
-		Mode:    degitInst.options.Mode,
+		Force:          true, // Force for nested clones
+		Cache:          action.Cache,
+		Verbose:        action.Verbose,
+		Token:          degitInst.options.Token,
+		Mode:           degitInst.options.Mode,
+		CacheZstd:      degitInst.options.CacheZstd,
+		Vars:           degitInst.vars,
+		NonInteractive: degitInst.options.NonInteractive,
+		MaxDepth:       degitInst.options.MaxDepth,
+		Include:        action.Include,
+		Exclude:        action.Exclude,
 	})
+	degitInst.nest(nestedDegit, dir)
+
+	sdk.Info(fmt.Sprintf("Cloning additional source: %s", action.Src))
+
+	// Clone into the destination or its dest subdirectory (will merge)
+	if err := nestedDegit.clone(src, target); err != nil {
+		return err
+	}
 
-	// Clone to the same destination (will merge)
-	return nestedDegit.Clone(src, destDir)
+	// Commands of the nested template run with the outer ones
+	degitInst.scripts = append(degitInst.scripts, nestedDegit.scripts...)
+	return nil
 }
 
 // executeRemoveAction executes a remove action (removes specified files)
@@ -168,6 +272,191 @@ func executeRemoveAction(action Action, destDir string) error {
 	return nil
 }
 
+// executeMoveAction executes a rename/move or copy action. A glob in
+// "from" may match several paths, which are then moved or copied into the
+// "to" directory.
+func executeMoveAction(action Action, destDir string) error {
+	if action.From == "" || action.To == "" {
+		return fmt.Errorf("%s action requires 'from' and 'to' fields", action.Action)
+	}
+
+	target := filepath.Join(destDir, action.To)
+	if !hasPrefix(target, destDir) {
+		sdk.Warning(fmt.Sprintf("Skipping path traversal attempt: %s", action.To))
+		return nil
+	}
+
+	isGlob := strings.ContainsAny(action.From, "*?[{")
+	sources := []string{action.From}
+	if isGlob {
+		matches, err := doublestar.Glob(os.DirFS(destDir), action.From)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %s: %w", action.From, err)
+		}
+		if len(matches) == 0 {
+			sdk.Warning(fmt.Sprintf("No files match: %s", action.From))
+			return nil
+		}
+		sources = matches
+	}
+
+	for _, source := range sources {
+		sourcePath := filepath.Join(destDir, filepath.FromSlash(source))
+		if !hasPrefix(sourcePath, destDir) || sourcePath == filepath.Clean(destDir) {
+			sdk.Warning(fmt.Sprintf("Skipping path traversal attempt: %s", source))
+			continue
+		}
+
+		targetPath := target
+		if isGlob {
+			targetPath = filepath.Join(target, filepath.Base(sourcePath))
+		}
+
+		if hasPrefix(targetPath, sourcePath) {
+			return fmt.Errorf("cannot %s %s into itself", action.Action, source)
+		}
+
+		err := moveOrCopy(action.Action, sourcePath, targetPath, destDir)
+		switch {
+		case errors.Is(err, errNoSource):
+			sdk.Warning(fmt.Sprintf("File does not exist: %s", source))
+			continue
+		case errors.Is(err, errLinkEscapes):
+			sdk.Warning(fmt.Sprintf("Skipping symlink pointing outside the destination: %s", source))
+			continue
+		case err != nil:
+			return fmt.Errorf("failed to %s %s: %w", action.Action, source, err)
+		}
+		rel, _ := filepath.Rel(destDir, targetPath)
+		sdk.Info(fmt.Sprintf("%s: %s -> %s", action.Action, source, filepath.ToSlash(rel)))
+	}
+
+	return nil
+}
+
+// Errors of moveOrCopy for sources that are skipped
+var (
+	errNoSource    = errors.New("source does not exist")
+	errLinkEscapes = errors.New("symlink points outside the destination")
+)
+
+// moveOrCopy moves (or, for the copy action, copies) a file or directory
+// to target, which must not exist yet. Symlinks are moved or recreated,
+// never followed, and must keep pointing inside destDir.
+func moveOrCopy(kind string, sourcePath string, targetPath string, destDir string) error {
+	info, err := os.Lstat(sourcePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errNoSource
+		}
+		return err
+	}
+
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		if link, err = os.Readlink(sourcePath); err != nil {
+			return err
+		}
+		if filepath.IsAbs(link) || !hasPrefix(filepath.Join(filepath.Dir(targetPath), link), destDir) {
+			return errLinkEscapes
+		}
+	}
+
+	if _, err := os.Lstat(targetPath); err == nil {
+		return fmt.Errorf("%s already exists", filepath.Base(targetPath))
+	}
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return err
+	}
+
+	switch {
+	case kind != "copy":
+		return os.Rename(sourcePath, targetPath)
+	case link != "":
+		return os.Symlink(link, targetPath)
+	case info.IsDir():
+		return copyDir(sourcePath, targetPath)
+	}
+	return copyFile(sourcePath, targetPath, info.Mode().Perm())
+}
+
+// executeReplaceAction executes a replace action (replaces text in the
+// files matching the action's globs, or in all files when none are given).
+// Binary files are left untouched.
+func executeReplaceAction(action Action, destDir string) error {
+	if action.Search == "" {
+		return fmt.Errorf("replace action requires 'search' field")
+	}
+
+	replace := func(text string) string {
+		return strings.ReplaceAll(text, action.Search, action.Replace)
+	}
+	if action.Regex {
+		re, err := regexp.Compile(action.Search)
+		if err != nil {
+			return fmt.Errorf("invalid regex %s: %w", action.Search, err)
+		}
+		replace = func(text string) string {
+			return re.ReplaceAllString(text, action.Replace)
+		}
+	}
+
+	patterns := action.Files
+	if len(patterns) == 0 {
+		patterns = []string{"**"}
+	}
+
+	// Globs are matched in an fs.FS, which cannot reach outside destDir
+	fsys := os.DirFS(destDir)
+	seen := make(map[string]bool)
+	changed := 0
+	for _, pattern := range patterns {
+		matches, err := doublestar.Glob(fsys, pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			if seen[match] {
+				continue
+			}
+			seen[match] = true
+
+			filePath := filepath.Join(destDir, filepath.FromSlash(match))
+			info, err := os.Lstat(filePath)
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				continue
+			}
+
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				return err
+			}
+			if isBinary(data) {
+				continue
+			}
+
+			replaced := replace(string(data))
+			if replaced == string(data) {
+				continue
+			}
+			if err := os.WriteFile(filePath, []byte(replaced), info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to write %s: %w", match, err)
+			}
+			changed++
+			if action.Verbose {
+				sdk.Info(fmt.Sprintf("Replaced in: %s", match))
+			}
+		}
+	}
+
+	sdk.Info(fmt.Sprintf("Replaced %s in %d file(s)", action.Search, changed))
+	return nil
+}
+
 // filepath.HasPrefix is not available in older Go versions, so we implement it
 func init() {
 	// This is a no-op, just a placeholder for the filepath.HasPrefix function below
@@ -0,0 +1,139 @@
+package degit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecuteMoveAction(t *testing.T) {
+	tests := []struct {
+		name   string
+		action Action
+		want   map[string]string // Files (or "-> target" for symlinks) after the action, by path
+		gone   []string          // Paths that must not exist after the action
+	}{
+		{
+			name:   "rename file",
+			action: Action{Action: "rename", From: "a.txt", To: "b.txt"},
+			want:   map[string]string{"b.txt": "a"},
+			gone:   []string{"a.txt"},
+		},
+		{
+			name:   "copy file",
+			action: Action{Action: "copy", From: "a.txt", To: "dir/b.txt"},
+			want:   map[string]string{"a.txt": "a", "dir/b.txt": "a"},
+		},
+		{
+			name:   "missing source",
+			action: Action{Action: "copy", From: "missing.txt", To: "b.txt"},
+			gone:   []string{"b.txt"},
+		},
+		{
+			name:   "copy symlink recreates it",
+			action: Action{Action: "copy", From: "sub/link", To: "sub/copy"},
+			want:   map[string]string{"sub/copy": "-> ../a.txt"},
+		},
+		{
+			name:   "copy symlink that would escape",
+			action: Action{Action: "copy", From: "sub/link", To: "copy"},
+			gone:   []string{"copy"},
+		},
+		{
+			name:   "copy symlink to outside file",
+			action: Action{Action: "copy", From: "outside", To: "copy"},
+			gone:   []string{"copy"},
+		},
+		{
+			name:   "copy glob",
+			action: Action{Action: "copy", From: "*.txt", To: "out"},
+			want:   map[string]string{"out/a.txt": "a", "a.txt": "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			secret := filepath.Join(root, "secret")
+			dest := filepath.Join(root, "dest")
+			writeTestFile(t, secret, "secret")
+			writeTestFile(t, filepath.Join(dest, "a.txt"), "a")
+			if err := os.Mkdir(filepath.Join(dest, "sub"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink("../a.txt", filepath.Join(dest, "sub", "link")); err != nil {
+				t.Skipf("symlinks not supported: %v", err)
+			}
+			if err := os.Symlink(secret, filepath.Join(dest, "outside")); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := executeMoveAction(tt.action, dest); err != nil {
+				t.Fatalf("executeMoveAction() error = %v", err)
+			}
+
+			for name, want := range tt.want {
+				path := filepath.Join(dest, filepath.FromSlash(name))
+				var got string
+				if link, err := os.Readlink(path); err == nil {
+					got = "-> " + link
+				} else if data, err := os.ReadFile(path); err == nil {
+					got = string(data)
+				} else {
+					t.Errorf("%s: %v", name, err)
+					continue
+				}
+				if got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			for _, name := range tt.gone {
+				if _, err := os.Lstat(filepath.Join(dest, filepath.FromSlash(name))); !os.IsNotExist(err) {
+					t.Errorf("%s exists, want it missing", name)
+				}
+			}
+		})
+	}
+}
+
+// writeTestFile writes a file, creating its directory
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecuteCloneActionLocalSource(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	tests := []struct {
+		name   string
+		parent *Source
+		src    string
+		reject bool // Whether the source is rejected as local
+	}{
+		{name: "absolute path in remote template", parent: &Source{Site: "github", Owner: "acme", Repo: "tpl"}, src: missing, reject: true},
+		{name: "relative path in remote template", parent: &Source{Site: "github", Owner: "acme", Repo: "tpl"}, src: "../other", reject: true},
+		{name: "file URL in archive template", parent: &Source{Site: "url", URL: "https://example.com/tpl.tar.gz"}, src: "file://" + missing, reject: true},
+		{name: "local path in local template", parent: &Source{Site: "local", Path: t.TempDir()}, src: missing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(Options{})
+			d.source = tt.parent
+
+			err := executeCloneAction(Action{Action: "clone", Src: tt.src}, t.TempDir(), d)
+			if err == nil {
+				t.Fatalf("executeCloneAction() succeeded, want an error")
+			}
+			if rejected := strings.Contains(err.Error(), "cannot use local source"); rejected != tt.reject {
+				t.Errorf("executeCloneAction() error = %v, rejected as local = %v, want %v", err, rejected, tt.reject)
+			}
+		})
+	}
+}
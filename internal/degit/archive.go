@@ -0,0 +1,139 @@
+package degit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// archiveURLRegex matches direct archive URLs such as
+// https://example.com/starter-1.4.tar.gz (query strings are allowed)
+var archiveURLRegex = regexp.MustCompile(`^https?://[^/\s]+/[^\s?#]*\.(?:tar\.gz|tgz|tar\.zst|tzst|tar\.xz|txz|zip)(?:\?[^\s#]*)?$`)
+
+// archiveExtensions maps the recognized archive suffixes to the extension
+// used in the cache
+var archiveExtensions = []struct {
+	suffix   string
+	cacheExt string
+}{
+	{".tar.gz", ".tar.gz"},
+	{".tgz", ".tar.gz"},
+	{".tar.zst", ".tar.zst"},
+	{".tzst", ".tar.zst"},
+	{".tar.xz", ".tar.xz"},
+	{".txz", ".tar.xz"},
+	{".zip", ".zip"},
+}
+
+// isArchiveURL reports whether a source string is a direct archive URL
+func isArchiveURL(src string) bool {
+	return archiveURLRegex.MatchString(src)
+}
+
+// parseArchiveSource parses a direct archive URL into a Source. The
+// archive's host becomes the owner and its file name (without extension)
+// the repo, so it is cached under GetCacheDir()/url/<host>/<name>.
+func parseArchiveSource(src string) (*Source, error) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse archive URL %s: %w", src, err)
+	}
+
+	name := path.Base(u.Path)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(name, ext.suffix) {
+			name = strings.TrimSuffix(name, ext.suffix)
+			break
+		}
+	}
+
+	return &Source{
+		Site:  "url",
+		Owner: u.Host,
+		Repo:  name,
+		URL:   src,
+	}, nil
+}
+
+// archiveFileExt returns the extension used to store an archive in the cache
+func archiveFileExt(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ".tar.gz"
+	}
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(u.Path, ext.suffix) {
+			return ext.cacheExt
+		}
+	}
+	return ".tar.gz"
+}
+
+// archiveCacheKey derives the cache key of an archive from its URL and the
+// validators returned by the server, so a changed upstream file gets a new key
+func archiveCacheKey(rawURL, etag, lastModified string) string {
+	sum := sha256.Sum256([]byte(rawURL + "\n" + etag + "\n" + lastModified))
+	return hex.EncodeToString(sum[:20])
+}
+
+// fetchArchiveValidators returns the ETag and Last-Modified headers of an archive
+func fetchArchiveValidators(rawURL string) (etag string, lastModified string, err error) {
+	resp, err := http.Head(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to reach %s: %w", rawURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Some servers reject HEAD; treat that as "no validators" rather than failing
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		return "", "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("archive request failed with status %d", resp.StatusCode)
+	}
+
+	return resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), nil
+}
+
+// ArchiveProvider implements Provider for direct archive URLs. Archives
+// have no refs; the URL itself is downloaded.
+type ArchiveProvider struct{}
+
+// Name returns the site key
+func (p *ArchiveProvider) Name() string {
+	return "url"
+}
+
+// BaseURL returns an empty string; archives can live on any host
+func (p *ArchiveProvider) BaseURL() string {
+	return ""
+}
+
+// FetchRefs always fails: archive URLs have no refs to resolve
+func (p *ArchiveProvider) FetchRefs(src *Source) ([]Ref, error) {
+	return nil, fmt.Errorf("archive source %s has no refs", src.URL)
+}
+
+// ArchiveURL returns the source URL unchanged
+func (p *ArchiveProvider) ArchiveURL(src *Source, hash string) string {
+	return src.URL
+}
+
+// Authenticate is a no-op for archive URLs
+func (p *ArchiveProvider) Authenticate(req *http.Request) {}
+
+// Download fetches the archive URL without authentication
+func (p *ArchiveProvider) Download(src *Source, hash string, destPath string, opts DownloadOptions) error {
+	return downloadPublic(src.URL, destPath)
+}
+
+// CheckAccess issues a HEAD request against the archive URL
+func (p *ArchiveProvider) CheckAccess(src *Source) (bool, error) {
+	_, _, err := fetchArchiveValidators(src.URL)
+	return err == nil, nil
+}
@@ -1,11 +1,17 @@
 package degit
 
 import (
+	"compress/gzip"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 // CacheDir returns the cache directory path for degit
@@ -142,8 +148,9 @@ func UpdateCache(cacheDir string, ref string, hash string) error {
 
 		// Clean up old tarball if no longer in use
 		if !hashInUse {
-			oldTarball := filepath.Join(cacheDir, oldHash+".tar.gz")
-			_ = os.Remove(oldTarball)
+			if oldTarball := GetCachedTarball(cacheDir, oldHash); oldTarball != "" {
+				_ = os.Remove(oldTarball)
+			}
 		}
 	}
 
@@ -152,15 +159,77 @@ func UpdateCache(cacheDir string, ref string, hash string) error {
 	return SaveRefMap(cacheDir, refMap)
 }
 
-// GetCachedTarball returns the path to a cached tarball if it exists
+// cachedArchiveExts lists the file extensions archives are cached with
+var cachedArchiveExts = []string{".tar.gz", ".tar.zst", ".tar.xz", ".zip"}
+
+// GetCachedTarball returns the path to a cached tarball (or zip archive) if it exists
 func GetCachedTarball(cacheDir string, hash string) string {
-	tarballPath := filepath.Join(cacheDir, hash+".tar.gz")
-	if _, err := os.Stat(tarballPath); err == nil {
-		return tarballPath
+	for _, ext := range cachedArchiveExts {
+		tarballPath := filepath.Join(cacheDir, hash+ext)
+		if _, err := os.Stat(tarballPath); err == nil {
+			return tarballPath
+		}
 	}
 	return ""
 }
 
+// RecompressZstd replaces a cached .tar.gz with a zstd-compressed .tar.zst
+// and returns the new path. Non-gzip archives are returned unchanged.
+func RecompressZstd(tarballPath string) (string, error) {
+	format, err := DetectArchiveFormat(tarballPath)
+	if err != nil {
+		return "", err
+	}
+	if format != FormatGzip {
+		return tarballPath, nil
+	}
+
+	in, err := os.Open(tarballPath)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = in.Close() }()
+
+	gzReader, err := gzip.NewReader(in)
+	if err != nil {
+		return "", fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer func() { _ = gzReader.Close() }()
+
+	zstPath := strings.TrimSuffix(tarballPath, ".tar.gz") + ".tar.zst"
+	tmpPath := zstPath + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	zstdWriter, err := zstd.NewWriter(out, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		_ = out.Close()
+		return "", err
+	}
+	if _, err := io.Copy(zstdWriter, gzReader); err != nil {
+		_ = zstdWriter.Close()
+		_ = out.Close()
+		return "", fmt.Errorf("failed to recompress tarball: %w", err)
+	}
+	if err := zstdWriter.Close(); err != nil {
+		_ = out.Close()
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmpPath, zstPath); err != nil {
+		return "", err
+	}
+	_ = os.Remove(tarballPath)
+
+	return zstPath, nil
+}
+
 // GetCachedHash returns the cached hash for a ref, if any
 func GetCachedHash(cacheDir string, ref string) string {
 	refMap, err := LoadRefMap(cacheDir)
@@ -186,6 +255,13 @@ func UpdateCacheAccess(cacheDir string, ref string) error {
 	return SaveAccessLog(cacheDir, accessLog)
 }
 
+// isArchiveCacheDir reports whether path is the cache directory of archive
+// sources (url/<host>/<name>), which are not repositories: their cache
+// path does not parse back to the source
+func isArchiveCacheDir(cacheDir string, path string, info os.FileInfo) bool {
+	return info.IsDir() && info.Name() == "url" && filepath.Dir(path) == filepath.Clean(cacheDir)
+}
+
 // GetCachedRepos returns a list of all cached repository paths
 // Format: "site/owner/repo"
 func GetCachedRepos() []string {
@@ -197,6 +273,9 @@ func GetCachedRepos() []string {
 		if err != nil {
 			return nil
 		}
+		if isArchiveCacheDir(cacheDir, path, info) {
+			return filepath.SkipDir
+		}
 
 		// Look for map.json files (indicates a cached repo)
 		if info.Name() == "map.json" {
@@ -228,6 +307,9 @@ func GetCachedReposByRecency() []string {
 		if err != nil {
 			return nil
 		}
+		if isArchiveCacheDir(cacheDir, path, info) {
+			return filepath.SkipDir
+		}
 
 		if info.Name() == "access.json" {
 			dir := filepath.Dir(path)
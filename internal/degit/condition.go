@@ -0,0 +1,203 @@
+package degit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// evalCondition evaluates an action's "if" expression against variables.
+//
+// Expressions compare variables with quoted strings or other variables
+// (ci == 'github', ci != "gitlab"), test membership in comma-separated
+// values such as multi-select answers ('docker' in features), and combine
+// tests with &&, || and ! and parentheses. A bare variable is true unless it
+// is empty, "false" or "0". Undefined variables are empty.
+func evalCondition(expr string, vars map[string]string) (bool, error) {
+	tokens, err := tokenizeCondition(expr)
+	if err != nil {
+		return false, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+
+	p := &conditionParser{tokens: tokens, vars: vars}
+	result, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return false, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+	return result, nil
+}
+
+// Condition token kinds
+const (
+	tokenIdent = iota
+	tokenString
+	tokenOp
+)
+
+// conditionToken is a lexical token of a condition
+type conditionToken struct {
+	kind int
+	text string
+}
+
+// tokenizeCondition splits a condition into tokens
+func tokenizeCondition(expr string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, conditionToken{tokenString, string(runes[i+1 : end])})
+			i = end + 1
+
+		case isIdentRune(r):
+			end := i
+			for end < len(runes) && isIdentRune(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, conditionToken{tokenIdent, string(runes[i:end])})
+			i = end
+
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "&&", "||", "!", "(", ")"} {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+			tokens = append(tokens, conditionToken{tokenOp, op})
+			i += len(op)
+		}
+	}
+
+	return tokens, nil
+}
+
+// isIdentRune reports whether r can be part of a variable name
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+// conditionParser is a recursive descent parser evaluating a condition
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+	vars   map[string]string
+}
+
+// accept consumes the next token if it is the given operator or keyword
+func (p *conditionParser) accept(text string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind != tokenString && p.tokens[p.pos].text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseOr parses: and ("||" and)*
+func (p *conditionParser) parseOr() (bool, error) {
+	result, err := p.parseAnd()
+	for err == nil && p.accept("||") {
+		var right bool
+		right, err = p.parseAnd()
+		result = result || right
+	}
+	return result, err
+}
+
+// parseAnd parses: unary ("&&" unary)*
+func (p *conditionParser) parseAnd() (bool, error) {
+	result, err := p.parseUnary()
+	for err == nil && p.accept("&&") {
+		var right bool
+		right, err = p.parseUnary()
+		result = result && right
+	}
+	return result, err
+}
+
+// parseUnary parses: "!" unary | "(" or ")" | comparison
+func (p *conditionParser) parseUnary() (bool, error) {
+	if p.accept("!") {
+		result, err := p.parseUnary()
+		return !result, err
+	}
+
+	if p.accept("(") {
+		result, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if !p.accept(")") {
+			return false, fmt.Errorf("missing )")
+		}
+		return result, nil
+	}
+
+	return p.parseComparison()
+}
+
+// parseComparison parses: operand (("==" | "!=" | "in") operand)?
+func (p *conditionParser) parseComparison() (bool, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case p.accept("=="):
+		right, err := p.parseOperand()
+		return left == right, err
+	case p.accept("!="):
+		right, err := p.parseOperand()
+		return left != right, err
+	case p.accept("in"):
+		right, err := p.parseOperand()
+		return slices.Contains(strings.Split(right, ","), left), err
+	}
+
+	return left != "" && left != "false" && left != "0", nil
+}
+
+// parseOperand parses a quoted string or a variable (true and false are
+// literals)
+func (p *conditionParser) parseOperand() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("unexpected end of expression")
+	}
+
+	token := p.tokens[p.pos]
+	switch token.kind {
+	case tokenString:
+		p.pos++
+		return token.text, nil
+	case tokenIdent:
+		p.pos++
+		if token.text == "true" || token.text == "false" {
+			return token.text, nil
+		}
+		return p.vars[token.text], nil
+	}
+
+	return "", fmt.Errorf("unexpected %q", token.text)
+}
@@ -0,0 +1,96 @@
+package degit
+
+import "testing"
+
+func TestEvalCondition(t *testing.T) {
+	vars := map[string]string{
+		"ci":       "github",
+		"docker":   "true",
+		"license":  "",
+		"count":    "0",
+		"features": "docker,lint,release-notes",
+		"name":     "github",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		// Comparisons
+		{`ci == 'github'`, true},
+		{`ci == "gitlab"`, false},
+		{`ci != 'gitlab'`, true},
+		{`ci == name`, true},
+		{`undefined == ''`, true},
+
+		// Bare variables and literals
+		{`docker`, true},
+		{`license`, false},
+		{`count`, false},
+		{`undefined`, false},
+		{`true`, true},
+		{`false`, false},
+
+		// Membership
+		{`'docker' in features`, true},
+		{`'lint' in features`, true},
+		{`'doc' in features`, false},
+		{`'release-notes' in features`, true},
+		{`ci in 'gitlab,github'`, true},
+		{`'docker' in undefined`, false},
+
+		// Negation
+		{`!docker`, false},
+		{`!license`, true},
+		{`!!docker`, true},
+		{`!('docker' in features)`, false},
+		{`!ci == 'github'`, false},
+
+		// Precedence: && binds tighter than ||
+		{`docker || license && false`, true},
+		{`license && false || docker`, true},
+		{`(docker || license) && false`, false},
+		{`false || false || ci == 'github'`, true},
+		{`docker && !license && 'lint' in features`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := evalCondition(tt.expr, vars)
+			if err != nil {
+				t.Fatalf("evalCondition() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("evalCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalConditionErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`ci == 'github`, `invalid condition "ci == 'github": unterminated string`},
+		{`ci = 'github'`, `invalid condition "ci = 'github'": unexpected character '='`},
+		{`ci ==`, `invalid condition "ci ==": unexpected end of expression`},
+		{`(docker`, `invalid condition "(docker": missing )`},
+		{`docker)`, `invalid condition "docker)": unexpected ")"`},
+		{`docker lint`, `invalid condition "docker lint": unexpected "lint"`},
+		{`&& docker`, `invalid condition "&& docker": unexpected "&&"`},
+		{``, `invalid condition "": unexpected end of expression`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := evalCondition(tt.expr, nil)
+			if err == nil {
+				t.Fatalf("evalCondition() succeeded, want error %q", tt.want)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("evalCondition() error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
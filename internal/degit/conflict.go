@@ -0,0 +1,239 @@
+package degit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+)
+
+// Conflict policies for files that already exist in the destination
+const (
+	ConflictOverwrite = "overwrite" // Replace the existing file
+	ConflictSkip      = "skip"      // Keep the existing file
+	ConflictError     = "error"     // Abort the clone without touching dest
+	ConflictBackup    = "backup"    // Rename the existing file to *.orig (or *.orig.N)
+	ConflictPrompt    = "prompt"    // Ask for each file
+)
+
+// conflictPolicies lists the valid conflict policies
+var conflictPolicies = []string{ConflictOverwrite, ConflictSkip, ConflictError, ConflictBackup, ConflictPrompt}
+
+// backupSuffix is appended to files moved aside by the backup policy
+const backupSuffix = ".orig"
+
+// ValidateConflictPolicy checks that policy is a known conflict policy
+func ValidateConflictPolicy(policy string) error {
+	for _, p := range conflictPolicies {
+		if policy == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown conflict policy %q (expected one of %s)", policy, strings.Join(conflictPolicies, ", "))
+}
+
+// findConflicts returns the paths (relative to staging) of staged entries
+// that collide with existing entries in dest. Directories present on both
+// sides are merged and are not conflicts.
+func findConflicts(staging string, dest string) ([]string, error) {
+	var conflicts []string
+
+	err := filepath.Walk(staging, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == staging {
+			return nil
+		}
+
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		}
+
+		destInfo, err := os.Lstat(filepath.Join(dest, rel))
+		if os.IsNotExist(err) {
+			// Nothing below a new directory can conflict
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && destInfo.IsDir() {
+			return nil
+		}
+
+		conflicts = append(conflicts, rel)
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	return conflicts, err
+}
+
+// resolveConflicts decides what to do with each conflicting path according
+// to policy, returning ConflictOverwrite, ConflictSkip or ConflictBackup for
+// each of them. The prompt policy fails like the error policy when prompts
+// cannot be shown.
+func resolveConflicts(conflicts []string, policy string, interactive bool) (map[string]string, error) {
+	decisions := make(map[string]string, len(conflicts))
+
+	switch policy {
+	case ConflictError:
+		if len(conflicts) > 0 {
+			return nil, fmt.Errorf("%d file(s) already exist in destination: %s", len(conflicts), strings.Join(conflicts, ", "))
+		}
+
+	case ConflictPrompt:
+		if !interactive && len(conflicts) > 0 {
+			return nil, fmt.Errorf("%d file(s) already exist in destination and cannot be prompted for in non-interactive mode (use --conflict=overwrite, skip or backup): %s", len(conflicts), strings.Join(conflicts, ", "))
+		}
+		all := ""
+		for _, rel := range conflicts {
+			if all != "" {
+				decisions[rel] = all
+				continue
+			}
+			choice, err := promptConflict(rel)
+			if err != nil {
+				return nil, err
+			}
+			if strings.HasSuffix(choice, " all") {
+				choice = strings.TrimSuffix(choice, " all")
+				all = choice
+			}
+			decisions[rel] = choice
+		}
+
+	default:
+		for _, rel := range conflicts {
+			decisions[rel] = policy
+		}
+	}
+
+	return decisions, nil
+}
+
+// promptConflict asks what to do with an existing file
+func promptConflict(rel string) (string, error) {
+	items := []string{
+		ConflictOverwrite,
+		ConflictSkip,
+		ConflictBackup,
+		ConflictOverwrite + " all",
+		ConflictSkip + " all",
+		ConflictBackup + " all",
+	}
+
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("%s already exists", filepath.ToSlash(rel)),
+		Items: items,
+		Templates: &promptui.SelectTemplates{
+			Label:    "{{ . }}",
+			Active:   "\U0001F449 {{ . | cyan }}",
+			Inactive: "  {{ . }}",
+			Selected: "{{ . | green }}",
+		},
+	}
+
+	idx, _, err := prompt.Run()
+	if err != nil {
+		if err == promptui.ErrInterrupt || err == promptui.ErrEOF {
+			return "", ErrUserCancelled
+		}
+		return "", fmt.Errorf("prompt failed: %w", err)
+	}
+
+	return items[idx], nil
+}
+
+// applyConflicts prepares staging and dest for merging: skipped paths are
+// dropped from staging and backed up paths are renamed in dest
+func applyConflicts(staging string, dest string, decisions map[string]string) error {
+	for rel, decision := range decisions {
+		switch decision {
+		case ConflictSkip:
+			if err := os.RemoveAll(filepath.Join(staging, rel)); err != nil {
+				return err
+			}
+		case ConflictBackup:
+			backupRel, err := backupName(staging, dest, rel)
+			if err != nil {
+				return err
+			}
+			if err := os.Rename(filepath.Join(dest, rel), filepath.Join(dest, backupRel)); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", rel, err)
+			}
+		}
+	}
+	return nil
+}
+
+// backupName returns the path (relative to dest) to move rel aside to: the
+// first of rel.orig, rel.orig.1, rel.orig.2, ... that neither exists in
+// dest nor is about to be created from staging, so no file is overwritten
+func backupName(staging string, dest string, rel string) (string, error) {
+	name := rel + backupSuffix
+	for i := 1; ; i++ {
+		taken := false
+		for _, dir := range []string{dest, staging} {
+			_, err := os.Lstat(filepath.Join(dir, name))
+			if err == nil {
+				taken = true
+				break
+			}
+			if !os.IsNotExist(err) {
+				return "", err
+			}
+		}
+		if !taken {
+			return name, nil
+		}
+		name = fmt.Sprintf("%s%s.%d", rel, backupSuffix, i)
+	}
+}
+
+// commitStagingWithPolicy moves the staged files into dest, resolving files
+// that already exist in dest according to policy. Conflicts are resolved
+// before anything is moved, so the error policy (or a cancelled prompt)
+// leaves dest untouched. It returns the slash-separated paths (relative to
+// staging) that were skipped and kept their content in dest.
+func commitStagingWithPolicy(staging string, dest string, policy string, interactive bool) ([]string, error) {
+	var skipped []string
+	if policy != ConflictOverwrite {
+		conflicts, err := findConflicts(staging, dest)
+		if err != nil {
+			return nil, err
+		}
+
+		decisions, err := resolveConflicts(conflicts, policy, interactive)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := applyConflicts(staging, dest, decisions); err != nil {
+			return nil, err
+		}
+
+		for rel, decision := range decisions {
+			if decision == ConflictSkip {
+				skipped = append(skipped, filepath.ToSlash(rel))
+			}
+		}
+		sort.Strings(skipped)
+	}
+
+	if err := commitStaging(staging, dest); err != nil {
+		return nil, fmt.Errorf("failed to move files into %s: %w", dest, err)
+	}
+	return skipped, nil
+}
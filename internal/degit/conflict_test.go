@@ -0,0 +1,157 @@
+package degit
+
+import (
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCommitStagingWithPolicy(t *testing.T) {
+	staged := map[string]string{"a.txt": "new", "b.txt": "b"}
+
+	tests := []struct {
+		name        string
+		policy      string
+		interactive bool
+		dest        map[string]string // Files in dest before the commit
+		want        map[string]string // Files in dest after the commit
+		skipped     []string          // Paths reported as skipped
+		wantErr     bool
+	}{
+		{
+			name:   "overwrite",
+			policy: ConflictOverwrite,
+			dest:   map[string]string{"a.txt": "old"},
+			want:   map[string]string{"a.txt": "new", "b.txt": "b"},
+		},
+		{
+			name:    "skip",
+			policy:  ConflictSkip,
+			dest:    map[string]string{"a.txt": "old"},
+			want:    map[string]string{"a.txt": "old", "b.txt": "b"},
+			skipped: []string{"a.txt"},
+		},
+		{
+			name:    "error leaves dest untouched",
+			policy:  ConflictError,
+			dest:    map[string]string{"a.txt": "old"},
+			want:    map[string]string{"a.txt": "old"},
+			wantErr: true,
+		},
+		{
+			name:   "error without conflicts",
+			policy: ConflictError,
+			dest:   map[string]string{"c.txt": "c"},
+			want:   map[string]string{"a.txt": "new", "b.txt": "b", "c.txt": "c"},
+		},
+		{
+			name:   "backup",
+			policy: ConflictBackup,
+			dest:   map[string]string{"a.txt": "old"},
+			want:   map[string]string{"a.txt": "new", "a.txt.orig": "old", "b.txt": "b"},
+		},
+		{
+			name:   "backup keeps existing .orig files",
+			policy: ConflictBackup,
+			dest:   map[string]string{"a.txt": "old", "a.txt.orig": "precious", "a.txt.orig.1": "precious 1"},
+			want:   map[string]string{"a.txt": "new", "a.txt.orig": "precious", "a.txt.orig.1": "precious 1", "a.txt.orig.2": "old", "b.txt": "b"},
+		},
+		{
+			name:    "prompt when not interactive",
+			policy:  ConflictPrompt,
+			dest:    map[string]string{"a.txt": "old"},
+			want:    map[string]string{"a.txt": "old"},
+			wantErr: true,
+		},
+		{
+			name:   "prompt without conflicts when not interactive",
+			policy: ConflictPrompt,
+			want:   map[string]string{"a.txt": "new", "b.txt": "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			staging := filepath.Join(root, "staging")
+			dest := filepath.Join(root, "dest")
+			writeTestTree(t, staging, staged)
+			writeTestTree(t, dest, tt.dest)
+
+			skipped, err := commitStagingWithPolicy(staging, dest, tt.policy, tt.interactive)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("commitStagingWithPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := readTestTree(t, dest); !maps.Equal(got, tt.want) {
+				t.Errorf("dest = %v, want %v", got, tt.want)
+			}
+			if !slices.Equal(skipped, tt.skipped) {
+				t.Errorf("skipped = %v, want %v", skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestResolveConflicts(t *testing.T) {
+	conflicts := []string{"a.txt", "dir/b.txt"}
+
+	for _, policy := range []string{ConflictOverwrite, ConflictSkip, ConflictBackup} {
+		t.Run(policy, func(t *testing.T) {
+			decisions, err := resolveConflicts(conflicts, policy, false)
+			if err != nil {
+				t.Fatalf("resolveConflicts() error = %v", err)
+			}
+			want := map[string]string{"a.txt": policy, "dir/b.txt": policy}
+			if !maps.Equal(decisions, want) {
+				t.Errorf("resolveConflicts() = %v, want %v", decisions, want)
+			}
+		})
+	}
+
+	for _, policy := range []string{ConflictError, ConflictPrompt} {
+		t.Run(policy, func(t *testing.T) {
+			if _, err := resolveConflicts(conflicts, policy, false); err == nil {
+				t.Errorf("resolveConflicts() succeeded, want an error")
+			}
+		})
+	}
+}
+
+// writeTestTree writes files (by slash-separated path) below dir
+func writeTestTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+	}
+}
+
+// readTestTree returns the regular files below dir by slash-separated path
+func readTestTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
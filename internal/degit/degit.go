@@ -4,23 +4,45 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"strings"
 
 	sdk "github.com/ssgohq/ss-plugin-sdk"
 )
 
 // Options configures the Degit behavior
 type Options struct {
-	Force   bool   // Allow cloning to non-empty directory
-	Cache   bool   // Only use cached files (offline mode)
-	Mode    string // "tar" or "git"
-	Verbose bool   // Enable verbose output
-	Token   string // GitHub token for private repos
+	Force          bool              // Allow cloning to non-empty directory
+	Cache          bool              // Only use cached files (offline mode)
+	Mode           string            // "tar" or "git"
+	Verbose        bool              // Enable verbose output
+	Token          string            // GitHub token for private repos
+	CacheZstd      bool              // Recompress cached tarballs with zstd
+	Include        []string          // Doublestar globs of files to extract (empty for all)
+	Exclude        []string          // Doublestar globs of files to skip
+	Mappings       []Mapping         // Subdirectory-to-destination mappings (relative to the source's Subdir)
+	Conflict       string            // Policy for files that already exist in dest (see Conflict* constants)
+	Lock           bool              // Record the source, hash and file checksums in dest/.degit.lock
+	LockFormat     string            // Format of the lock file (LockJSON or LockYAML)
+	Version        string            // Tool version recorded in the lock file
+	Vars           map[string]string // Template variable values (others are prompted for)
+	NonInteractive bool              // Never prompt; use defaults for missing values
+	AllowScripts   bool              // Run "run" actions of any template (not only trusted owners')
+	MaxDepth       int               // How deeply clone actions may nest (0 for DefaultMaxDepth)
 }
 
 // Degit is the main struct for degit operations
 type Degit struct {
 	options Options
+	vars    map[string]string // Template variables resolved during the clone
+	source  *Source           // Source being cloned
+	scripts []script          // Commands of "run" actions, executed once dest is complete
+	graph   *cloneGraph       // Sources cloned for the scaffold (shared with nested clones)
+	node    *SourceNode       // Node of the source being cloned
+	parent  *SourceNode       // Node of the source whose clone action started this clone
+	path    []string          // Keys of the sources from the root to this one
+	dir     string            // Directory of this clone, relative to the scaffold root
 }
 
 // New creates a new Degit instance
@@ -28,40 +50,102 @@ func New(opts Options) *Degit {
 	if opts.Mode == "" {
 		opts.Mode = "tar"
 	}
+	// --force overwrites existing files unless another policy is set
+	if opts.Conflict == "" && opts.Force {
+		opts.Conflict = ConflictOverwrite
+	}
 	return &Degit{options: opts}
 }
 
-// Clone clones a repository to the destination directory
+// Clone clones a repository to the destination directory.
+//
+// Everything (download, extraction and degit.json actions) happens in a
+// staging directory next to dest, which is only merged into dest once all
+// steps succeeded, so a failure leaves dest exactly as it was. Commands of
+// "run" actions are executed last, in dest.
 func (d *Degit) Clone(src *Source, dest string) error {
-	// Check if destination is empty
-	if !d.options.Force {
+	if err := d.clone(src, dest); err != nil {
+		return err
+	}
+	return d.runScripts(dest)
+}
+
+// clone clones a repository to the destination directory through a
+// staging directory, queueing the commands of "run" actions
+func (d *Degit) clone(src *Source, dest string) error {
+	// Check if destination is empty, unless a conflict policy is set
+	if d.options.Conflict == "" {
 		if err := d.checkDestEmpty(dest); err != nil {
 			return err
 		}
 	}
 
+	staging, err := newStagingDir(dest)
+	if err != nil {
+		return err
+	}
+	// No-op once the staging directory has been committed
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	hash, err := d.cloneInto(src, staging)
+	if err != nil {
+		return err
+	}
+
+	// Checksum the scaffolded files before they are merged with existing ones
+	var lock *Lock
+	if d.options.Lock {
+		if lock, err = d.newLock(src, hash, staging); err != nil {
+			return fmt.Errorf("failed to checksum files: %w", err)
+		}
+	}
+
+	skipped, err := commitStagingWithPolicy(staging, dest, d.options.Conflict, d.interactive())
+	if err != nil {
+		return err
+	}
+
+	// Record where the project came from (used by update)
+	if lock != nil {
+		lock.forgetFiles(skipped)
+		if err := WriteLock(dest, lock); err != nil {
+			sdk.Warning(fmt.Sprintf("Failed to write %s: %v", LockFileName, err))
+		}
+	}
+
+	return nil
+}
+
+// cloneInto clones a repository into dir and runs its degit.json actions.
+// It returns the resolved commit hash (empty if it could not be determined).
+func (d *Degit) cloneInto(src *Source, dest string) (string, error) {
 	// Get cache directory
 	cacheDir := GetRepoCacheDir(src)
 
+	// Local and archive sources are always extracted from an archive
+	useGit := d.options.Mode == "git" && !src.IsLocal() && !src.IsArchive()
+
 	// Clone based on mode
+	var hash string
 	var err error
 	var usedGitMode bool
-	if d.options.Mode == "git" {
-		err = d.cloneWithGit(src, dest)
+	if useGit {
+		hash, err = d.cloneWithGit(src, dest)
 		usedGitMode = true
 	} else {
-		err = d.cloneWithTar(src, dest, cacheDir)
+		hash, err = d.cloneWithTar(src, dest, cacheDir)
 		// If tar mode fails, automatically try git mode as fallback
-		if err != nil {
+		if err != nil && !src.IsLocal() && !src.IsArchive() {
 			if d.options.Verbose {
 				sdk.Warning(fmt.Sprintf("Tarball download failed: %v", err))
 				sdk.Info("Falling back to git clone mode...")
 			}
 			// Clean up any partial extraction
 			_ = os.RemoveAll(dest)
-			gitErr := d.cloneWithGit(src, dest)
+			var gitErr error
+			hash, gitErr = d.cloneWithGit(src, dest)
 			if gitErr != nil {
-				return fmt.Errorf("tarball download failed (%v) and git clone also failed (%v)", err, gitErr)
+				return "", fmt.Errorf("tarball download failed (%v) and git clone also failed (%v)", err, gitErr)
 			}
 			err = nil // Git clone succeeded
 			usedGitMode = true
@@ -69,7 +153,7 @@ func (d *Degit) Clone(src *Source, dest string) error {
 	}
 
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Track access for interactive mode (even for git mode clones)
@@ -80,50 +164,146 @@ func (d *Degit) Clone(src *Source, dest string) error {
 		}
 	}
 
-	// Execute actions from degit.json if present
-	actions, loadErr := LoadActions(dest)
-	if loadErr != nil {
-		sdk.Warning(fmt.Sprintf("Failed to load degit.json: %v", loadErr))
-	} else if len(actions) > 0 {
+	d.source = src
+	d.enterSource(src, hash)
+
+	// Load variables and actions from degit.json (or .jsonc, .yaml) if present
+	manifest, err := LoadManifest(dest)
+	if err != nil {
+		return "", err
+	}
+
+	// Substitute template variables
+	vars, err := d.resolveVariables(manifest.Variables)
+	if err != nil {
+		return "", err
+	}
+	d.vars = vars
+	if len(vars) > 0 {
+		if err := renderTemplate(dest, vars); err != nil {
+			return "", fmt.Errorf("failed to substitute variables: %w", err)
+		}
+	}
+
+	// Execute actions from degit.json
+	if actions := manifest.Actions; len(actions) > 0 {
 		if d.options.Verbose {
 			sdk.Info(fmt.Sprintf("Executing %d actions from degit.json", len(actions)))
 		}
 		if execErr := ExecuteActions(actions, dest, d); execErr != nil {
-			return fmt.Errorf("failed to execute actions: %w", execErr)
+			return "", fmt.Errorf("failed to execute actions: %w", execErr)
 		}
 	}
 
-	return nil
+	return hash, nil
+}
+
+// fetchedArchive is an archive ready for extraction
+type fetchedArchive struct {
+	Path    string         // Archive file (in the cache or a temp directory)
+	Hash    string         // Resolved commit hash, or cache key for archive URLs
+	Options ExtractOptions // Extraction options for the source
+	cleanup func()         // Removes temporary files
 }
 
-// cloneWithTar clones using tarball download (fast, no git history)
-func (d *Degit) cloneWithTar(src *Source, dest string, cacheDir string) error {
+// Close removes any temporary files of the archive
+func (a *fetchedArchive) Close() {
+	if a.cleanup != nil {
+		a.cleanup()
+	}
+}
+
+// fetch resolves the source and returns its archive, downloading it into
+// the cache (or building it, for local sources) when needed
+func (d *Degit) fetch(src *Source, cacheDir string) (*fetchedArchive, error) {
+	if src.IsLocal() {
+		return d.fetchLocal(src)
+	}
+	if src.IsArchive() {
+		return d.fetchArchive(src, cacheDir)
+	}
+
+	hash, err := d.resolveHash(src, cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	tarballPath, err := d.fetchTarball(src, cacheDir, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update cache
+	if err := UpdateCache(cacheDir, src.Ref, hash); err != nil && d.options.Verbose {
+		sdk.Warning(fmt.Sprintf("Failed to update cache: %v", err))
+	}
+
+	return &fetchedArchive{
+		Path:    tarballPath,
+		Hash:    hash,
+		Options: d.extractOptions(src, 1),
+	}, nil
+}
+
+// extractOptions returns the extraction options for a source
+func (d *Degit) extractOptions(src *Source, stripComponents int) ExtractOptions {
+	return ExtractOptions{
+		StripComponents: stripComponents,
+		Subdir:          src.Subdir,
+		Include:         d.options.Include,
+		Exclude:         d.options.Exclude,
+		Mappings:        d.mappings(src),
+	}
+}
+
+// cloneWithTar clones using tarball download (fast, no git history) and
+// returns the resolved hash
+func (d *Degit) cloneWithTar(src *Source, dest string, cacheDir string) (string, error) {
+	archive, err := d.fetch(src, cacheDir)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+
+	// Extract tarball
+	if d.options.Verbose {
+		sdk.Info(fmt.Sprintf("Extracting to %s", dest))
+	}
+
+	if err := ExtractArchive(archive.Path, dest, archive.Options); err != nil {
+		return "", fmt.Errorf("failed to extract tarball: %w", err)
+	}
+
+	return archive.Hash, nil
+}
+
+// resolveHash resolves the source's ref to a commit hash, falling back to
+// the cached mapping when offline or when refs cannot be fetched
+func (d *Degit) resolveHash(src *Source, cacheDir string) (string, error) {
 	var hash string
 	var err error
 
+	// A full commit hash needs no resolution
+	if len(src.Ref) == 40 && isHex(src.Ref) {
+		return src.Ref, nil
+	}
+
 	// Try to resolve ref to hash
 	if d.options.Cache {
 		// Only use cache, don't fetch refs
 		hash = GetCachedHash(cacheDir, src.Ref)
 		if hash == "" {
-			return fmt.Errorf("ref %s not found in cache (offline mode)", src.Ref)
+			return "", fmt.Errorf("ref %s not found in cache (offline mode)", src.Ref)
 		}
 	} else {
-		// Fetch refs from remote (use API for GitHub if token available)
-		var refs []Ref
-		var fetchErr error
-
-		if src.Site == "github" {
-			refs, fetchErr = FetchRefsWithToken(src)
-		} else {
-			refs, fetchErr = FetchRefs(src.URL)
-		}
+		// Fetch refs from remote (the provider uses its API if a token is available)
+		refs, fetchErr := FetchRefsWithToken(src)
 
 		if fetchErr != nil {
 			// Try fallback to cached hash
 			hash = GetCachedHash(cacheDir, src.Ref)
 			if hash == "" {
-				return fmt.Errorf("could not fetch refs and no cache available: %w", fetchErr)
+				return "", fmt.Errorf("could not fetch refs and no cache available: %w", fetchErr)
 			}
 			if d.options.Verbose {
 				sdk.Warning("Could not fetch refs, using cached version")
@@ -132,7 +312,7 @@ func (d *Degit) cloneWithTar(src *Source, dest string, cacheDir string) error {
 			// Resolve ref to hash
 			hash, err = ResolveRef(refs, src.Ref)
 			if err != nil {
-				return fmt.Errorf("could not resolve ref %s: %w", src.Ref, err)
+				return "", fmt.Errorf("could not resolve ref %s: %w", src.Ref, err)
 			}
 		}
 	}
@@ -141,12 +321,18 @@ func (d *Degit) cloneWithTar(src *Source, dest string, cacheDir string) error {
 		sdk.Info(fmt.Sprintf("Resolved %s to %s", src.Ref, hash[:8]))
 	}
 
+	return hash, nil
+}
+
+// fetchTarball returns the cached tarball for a commit hash, downloading
+// it first if needed
+func (d *Degit) fetchTarball(src *Source, cacheDir string, hash string) (string, error) {
 	// Check for cached tarball
 	tarballPath := GetCachedTarball(cacheDir, hash)
 
 	if tarballPath == "" {
 		if d.options.Cache {
-			return fmt.Errorf("tarball for %s not found in cache (offline mode)", hash[:8])
+			return "", fmt.Errorf("tarball for %s not found in cache (offline mode)", hash[:8])
 		}
 
 		// Download tarball
@@ -155,44 +341,141 @@ func (d *Degit) cloneWithTar(src *Source, dest string, cacheDir string) error {
 			sdk.Info(fmt.Sprintf("Downloading %s", src.TarballURL(hash)))
 		}
 
-		err = DownloadTarball(src, hash, tarballPath, DownloadOptions{
+		err := DownloadTarball(src, hash, tarballPath, DownloadOptions{
 			Token:   d.options.Token,
 			Verbose: d.options.Verbose,
 		})
 		if err != nil {
-			return fmt.Errorf("failed to download tarball: %w", err)
+			return "", fmt.Errorf("failed to download tarball: %w", err)
 		}
+		tarballPath = d.compressCached(tarballPath)
 	} else if d.options.Verbose {
 		sdk.Info("Using cached tarball")
 	}
 
-	// Update cache
-	if err := UpdateCache(cacheDir, src.Ref, hash); err != nil && d.options.Verbose {
-		sdk.Warning(fmt.Sprintf("Failed to update cache: %v", err))
+	return tarballPath, nil
+}
+
+// fetchLocal archives a commit of a local repository into a temp directory
+// using git archive
+func (d *Degit) fetchLocal(src *Source) (*fetchedArchive, error) {
+	hash, err := resolveLocalRef(src.Path, src.Ref)
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract tarball
 	if d.options.Verbose {
-		sdk.Info(fmt.Sprintf("Extracting to %s", dest))
+		sdk.Info(fmt.Sprintf("Resolved %s to %s", src.Ref, hash[:8]))
 	}
 
-	extractOpts := ExtractOptions{
-		StripComponents: 1,
-		Subdir:          src.Subdir,
+	tempDir, err := os.MkdirTemp("", "degit-local-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
+	cleanup := func() { _ = os.RemoveAll(tempDir) }
 
-	if err := ExtractTarball(tarballPath, dest, extractOpts); err != nil {
-		return fmt.Errorf("failed to extract tarball: %w", err)
+	tarballPath := filepath.Join(tempDir, hash+".tar.gz")
+	if err := DownloadTarball(src, hash, tarballPath, DownloadOptions{Verbose: d.options.Verbose}); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to archive local repository: %w", err)
 	}
 
-	return nil
+	return &fetchedArchive{
+		Path:    tarballPath,
+		Hash:    hash,
+		Options: d.extractOptions(src, 1),
+		cleanup: cleanup,
+	}, nil
+}
+
+// fetchArchive downloads a direct archive URL. The archive is cached under
+// a key derived from its URL and ETag/Last-Modified, and the number of
+// components to strip is detected from its contents.
+func (d *Degit) fetchArchive(src *Source, cacheDir string) (*fetchedArchive, error) {
+	var hash string
+	revalidate := false
+
+	if d.options.Cache {
+		hash = GetCachedHash(cacheDir, src.URL)
+		if hash == "" {
+			return nil, fmt.Errorf("archive %s not found in cache (offline mode)", src.URL)
+		}
+	} else {
+		etag, lastModified, err := fetchArchiveValidators(src.URL)
+		if err != nil {
+			// Try fallback to cached archive
+			hash = GetCachedHash(cacheDir, src.URL)
+			if hash == "" {
+				return nil, err
+			}
+			if d.options.Verbose {
+				sdk.Warning("Could not reach archive URL, using cached version")
+			}
+		} else {
+			hash = archiveCacheKey(src.URL, etag, lastModified)
+			// Without validators there is no way to tell if the cached copy is current
+			revalidate = etag == "" && lastModified == ""
+		}
+	}
+
+	archivePath := GetCachedTarball(cacheDir, hash)
+
+	if archivePath == "" || revalidate {
+		if d.options.Cache {
+			return nil, fmt.Errorf("archive %s not found in cache (offline mode)", src.URL)
+		}
+
+		archivePath = filepath.Join(cacheDir, hash+archiveFileExt(src.URL))
+		if d.options.Verbose {
+			sdk.Info(fmt.Sprintf("Downloading %s", src.URL))
+		}
+
+		if err := DownloadTarball(src, hash, archivePath, DownloadOptions{Verbose: d.options.Verbose}); err != nil {
+			return nil, fmt.Errorf("failed to download archive: %w", err)
+		}
+		archivePath = d.compressCached(archivePath)
+	} else if d.options.Verbose {
+		sdk.Info("Using cached archive")
+	}
+
+	// Update cache (the URL takes the place of the ref)
+	if err := UpdateCache(cacheDir, src.URL, hash); err != nil && d.options.Verbose {
+		sdk.Warning(fmt.Sprintf("Failed to update cache: %v", err))
+	}
+
+	stripComponents, err := DetectStripComponents(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	return &fetchedArchive{
+		Path:    archivePath,
+		Hash:    hash,
+		Options: d.extractOptions(src, stripComponents),
+	}, nil
+}
+
+// compressCached recompresses a freshly downloaded tarball with zstd when
+// enabled, returning the path to use. Failures keep the original file.
+func (d *Degit) compressCached(tarballPath string) string {
+	if !d.options.CacheZstd {
+		return tarballPath
+	}
+	zstPath, err := RecompressZstd(tarballPath)
+	if err != nil {
+		if d.options.Verbose {
+			sdk.Warning(fmt.Sprintf("Failed to recompress cached tarball: %v", err))
+		}
+		return tarballPath
+	}
+	return zstPath
 }
 
 // cloneWithGit clones using git (slower, but works when tarball download fails)
-func (d *Degit) cloneWithGit(src *Source, dest string) error {
+func (d *Degit) cloneWithGit(src *Source, dest string) (string, error) {
 	// Check if git is available
 	if _, err := exec.LookPath("git"); err != nil {
-		return fmt.Errorf("git not found in PATH")
+		return "", fmt.Errorf("git not found in PATH")
 	}
 
 	// Try HTTPS first (works with credential helpers), fallback to SSH
@@ -217,7 +500,7 @@ func (d *Degit) cloneWithGit(src *Source, dest string) error {
 		cmd.Stderr = os.Stderr
 
 		if sshErr := cmd.Run(); sshErr != nil {
-			return fmt.Errorf("git clone failed (HTTPS: %v, SSH: %v)", err, sshErr)
+			return "", fmt.Errorf("git clone failed (HTTPS: %v, SSH: %v)", err, sshErr)
 		}
 	}
 
@@ -236,34 +519,87 @@ func (d *Degit) cloneWithGit(src *Source, dest string) error {
 		}
 	}
 
+	// Record the checked out commit before the history goes away
+	var hash string
+	if out, err := exec.Command("git", "-C", dest, "rev-parse", "HEAD").Output(); err == nil {
+		hash = strings.TrimSpace(string(out))
+	}
+
 	// Remove .git directory
 	gitDir := filepath.Join(dest, ".git")
 	if err := os.RemoveAll(gitDir); err != nil {
 		sdk.Warning(fmt.Sprintf("Failed to remove .git directory: %v", err))
 	}
 
-	// Handle subdirectory extraction for git mode
-	if src.Subdir != "" {
+	// Handle mappings for git mode: move the clone aside and copy each
+	// mapped subdirectory back into place
+	mappings := d.mappings(src)
+	if len(mappings) > 0 {
+		tempDir, err := os.MkdirTemp("", "degit-map-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(tempDir) }()
+
+		entries, _ := os.ReadDir(dest)
+		for _, entry := range entries {
+			srcPath := filepath.Join(dest, entry.Name())
+			dstPath := filepath.Join(tempDir, entry.Name())
+			if err := os.Rename(srcPath, dstPath); err != nil {
+				return "", fmt.Errorf("failed to move file: %w", err)
+			}
+		}
+
+		for _, m := range mappings {
+			mappedDest := filepath.Join(dest, m.Dest)
+			if !hasPrefix(mappedDest, dest) {
+				return "", fmt.Errorf("mapping destination escapes %s: %s", dest, m.Dest)
+			}
+			subdir := filepath.Join(tempDir, m.Subdir)
+			if len(d.options.Include) > 0 || len(d.options.Exclude) > 0 {
+				// Filter on paths relative to the mapped subdirectory, as
+				// tar mode does, in a copy so overlapping mappings each see
+				// all of their files
+				filtered, err := os.MkdirTemp("", "degit-filter-")
+				if err != nil {
+					return "", fmt.Errorf("failed to create temp directory: %w", err)
+				}
+				defer func() { _ = os.RemoveAll(filtered) }()
+
+				if err := copyDir(subdir, filtered); err != nil {
+					return "", fmt.Errorf("subdirectory %s not found: %w", m.Subdir, err)
+				}
+				if err := pruneFiltered(filtered, d.options.Include, d.options.Exclude); err != nil {
+					return "", fmt.Errorf("failed to apply filters: %w", err)
+				}
+				subdir = filtered
+			}
+			if err := copyDir(subdir, mappedDest); err != nil {
+				return "", fmt.Errorf("subdirectory %s not found: %w", m.Subdir, err)
+			}
+		}
+	} else if src.Subdir != "" {
+		// Handle subdirectory extraction for git mode
 		subdir := filepath.Join(dest, src.Subdir)
 
 		// Create temp directory
 		tempDir, err := os.MkdirTemp("", "degit-subdir-")
 		if err != nil {
-			return fmt.Errorf("failed to create temp directory: %w", err)
+			return "", fmt.Errorf("failed to create temp directory: %w", err)
 		}
 		defer func() { _ = os.RemoveAll(tempDir) }()
 
 		// Move subdirectory contents to temp
 		entries, err := os.ReadDir(subdir)
 		if err != nil {
-			return fmt.Errorf("subdirectory %s not found: %w", src.Subdir, err)
+			return "", fmt.Errorf("subdirectory %s not found: %w", src.Subdir, err)
 		}
 
 		for _, entry := range entries {
 			srcPath := filepath.Join(subdir, entry.Name())
 			dstPath := filepath.Join(tempDir, entry.Name())
 			if err := os.Rename(srcPath, dstPath); err != nil {
-				return fmt.Errorf("failed to move file: %w", err)
+				return "", fmt.Errorf("failed to move file: %w", err)
 			}
 		}
 
@@ -279,12 +615,37 @@ func (d *Degit) cloneWithGit(src *Source, dest string) error {
 			srcPath := filepath.Join(tempDir, entry.Name())
 			dstPath := filepath.Join(dest, entry.Name())
 			if err := os.Rename(srcPath, dstPath); err != nil {
-				return fmt.Errorf("failed to move file: %w", err)
+				return "", fmt.Errorf("failed to move file: %w", err)
 			}
 		}
 	}
 
-	return nil
+	// Apply include/exclude filters after the fact (git writes everything);
+	// dest now holds the source's subdirectory, so paths are relative to it
+	// as in tar mode. Mapped subdirectories were filtered above.
+	if len(mappings) == 0 {
+		if err := pruneFiltered(dest, d.options.Include, d.options.Exclude); err != nil {
+			return "", fmt.Errorf("failed to apply filters: %w", err)
+		}
+	}
+
+	return hash, nil
+}
+
+// mappings returns the configured mappings with subdirectories resolved
+// relative to the source's Subdir
+func (d *Degit) mappings(src *Source) []Mapping {
+	if len(d.options.Mappings) == 0 {
+		return nil
+	}
+	mappings := make([]Mapping, len(d.options.Mappings))
+	for i, m := range d.options.Mappings {
+		mappings[i] = Mapping{
+			Subdir: strings.Trim(path.Join(src.Subdir, m.Subdir), "/"),
+			Dest:   m.Dest,
+		}
+	}
+	return mappings
 }
 
 // checkDestEmpty checks if the destination directory is empty
@@ -298,7 +659,7 @@ func (d *Degit) checkDestEmpty(dest string) error {
 	}
 
 	if len(entries) > 0 {
-		return fmt.Errorf("destination %s is not empty (use --force to override)", dest)
+		return fmt.Errorf("destination %s is not empty (use --force or --conflict to override)", dest)
 	}
 
 	return nil
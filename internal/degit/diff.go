@@ -0,0 +1,207 @@
+package degit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff"
+)
+
+// Diff statuses for files
+const (
+	DiffModified = "modified" // Differs from the template
+	DiffAdded    = "added"    // Only in the project
+	DiffRemoved  = "removed"  // Only in the template
+)
+
+// DiffEntry describes how a project file differs from the template
+type DiffEntry struct {
+	Path   string // Path relative to the project
+	Status string // One of the Diff* constants
+	Patch  string // Unified diff from the template to the project (modified text files only)
+}
+
+// DiffResult describes the differences between a project and its template
+type DiffResult struct {
+	Hash    string      // Commit hash of the template compared against
+	Entries []DiffEntry // Differences, sorted by path
+}
+
+// Diff compares dest against the template it was cloned from, at the
+// recorded hash or at ref if given. The template is extracted into a temp
+// directory (from the cache when possible). Project files excluded by the
+// recorded filters or ignored by git, and the .git directory, are ignored.
+func (d *Degit) Diff(dest string, ref string) (*DiffResult, error) {
+	lock, err := ReadLock(dest)
+	if err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		ref = lock.Hash
+	}
+	if ref == "" {
+		return nil, fmt.Errorf("no commit hash recorded in %s", LockFileName)
+	}
+
+	src, err := lock.ParseSource(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "degit-diff-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	templateDir := filepath.Join(tempDir, "template")
+	hash, err := d.forLock(lock).cloneInto(src, templateDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch template at %s: %w", src.Ref, err)
+	}
+
+	templatePaths, err := templateFiles(templateDir)
+	if err != nil {
+		return nil, err
+	}
+	localPaths, err := projectFiles(dest, lock.Include, lock.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResult{Hash: hash}
+	inTemplate := make(map[string]bool, len(templatePaths))
+
+	for _, rel := range templatePaths {
+		inTemplate[rel] = true
+		entry, err := diffFile(rel, templateDir, dest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s: %w", rel, err)
+		}
+		if entry != nil {
+			result.Entries = append(result.Entries, *entry)
+		}
+	}
+
+	for _, rel := range localPaths {
+		if !inTemplate[rel] {
+			result.Entries = append(result.Entries, DiffEntry{Path: rel, Status: DiffAdded})
+		}
+	}
+
+	sort.Slice(result.Entries, func(i, j int) bool {
+		return result.Entries[i].Path < result.Entries[j].Path
+	})
+
+	return result, nil
+}
+
+// diffFile compares a template file with its project counterpart, returning
+// nil if they are identical
+func diffFile(rel string, templateDir string, dest string) (*DiffEntry, error) {
+	name := filepath.FromSlash(rel)
+
+	template, err := os.ReadFile(filepath.Join(templateDir, name))
+	if err != nil {
+		return nil, err
+	}
+	local, hasLocal, err := readOptional(filepath.Join(dest, name))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !hasLocal:
+		return &DiffEntry{Path: rel, Status: DiffRemoved}, nil
+	case bytes.Equal(template, local):
+		return nil, nil
+	}
+
+	entry := &DiffEntry{Path: rel, Status: DiffModified}
+	if isBinary(template) || isBinary(local) {
+		entry.Patch = fmt.Sprintf("Binary files a/%s and b/%s differ\n", rel, rel)
+	} else {
+		entry.Patch = udiff.Unified("a/"+rel, "b/"+rel, string(template), string(local))
+	}
+	return entry, nil
+}
+
+// projectFiles returns the sorted relative paths of the regular files in
+// dest that pass the include/exclude filters, skipping the lock file. When
+// dest is in a git work tree, only files git tracks or would track are
+// listed, so ignored trees (node_modules, build output, ...) are left out;
+// otherwise every file below dest except .git is.
+func projectFiles(dest string, include []string, exclude []string) ([]string, error) {
+	files, ok := gitFiles(dest)
+	if !ok {
+		var err error
+		if files, err = walkFiles(dest); err != nil {
+			return nil, err
+		}
+	}
+
+	var paths []string
+	for _, rel := range files {
+		if rel != LockFileName && matchesFilters(rel, include, exclude) {
+			paths = append(paths, rel)
+		}
+	}
+	return paths, nil
+}
+
+// gitFiles lists the tracked and untracked but not ignored regular files of
+// dest with git ls-files, reporting false if dest is not in a git work tree
+func gitFiles(dest string) ([]string, bool) {
+	out, err := exec.Command("git", "-C", dest, "ls-files", "-z", "--cached", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, false
+	}
+
+	var files []string
+	for _, rel := range strings.Split(string(out), "\x00") {
+		if rel == "" {
+			continue
+		}
+		// Tracked files may have been deleted, and submodules are listed as
+		// directories
+		info, err := os.Lstat(filepath.Join(dest, filepath.FromSlash(rel)))
+		if err == nil && info.Mode().IsRegular() {
+			files = append(files, rel)
+		}
+	}
+	sort.Strings(files)
+	return slices.Compact(files), true
+}
+
+// walkFiles returns the sorted relative paths of the regular files in dest,
+// skipping .git directories
+func walkFiles(dest string) ([]string, error) {
+	var files []string
+
+	err := filepath.Walk(dest, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dest, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+
+	return files, err
+}
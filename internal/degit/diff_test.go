@@ -0,0 +1,68 @@
+package degit
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		gitRepo bool              // Whether the project is made a git repository
+		local   map[string]string // Local changes made after the clone ("" removes a file)
+		want    map[string]string // Status by path
+	}{
+		{
+			name:  "modified, added and removed files",
+			local: map[string]string{"a.txt": "a local\n", "b.txt": "", "c.txt": "c\n"},
+			want:  map[string]string{"a.txt": DiffModified, "b.txt": DiffRemoved, "c.txt": DiffAdded},
+		},
+		{
+			name:  "every file without git",
+			local: map[string]string{"node_modules/x/index.js": "x\n", ".gitignore": "node_modules/\n"},
+			want:  map[string]string{"node_modules/x/index.js": DiffAdded, ".gitignore": DiffAdded},
+		},
+		{
+			name:    "files ignored by git",
+			gitRepo: true,
+			local:   map[string]string{"node_modules/x/index.js": "x\n", ".gitignore": "node_modules/\n", "c.txt": "c\n"},
+			want:    map[string]string{".gitignore": DiffAdded, "c.txt": DiffAdded},
+		},
+		{
+			name:  "files excluded by the recorded filters",
+			opts:  Options{Exclude: []string{"docs"}},
+			local: map[string]string{"docs/guide.md": "guide\n", "c.txt": "c\n"},
+			want:  map[string]string{"c.txt": DiffAdded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			repo := newTestRepo(t, map[string]string{"a.txt": "a\n", "b.txt": "b\n", "docs/index.md": "docs\n"})
+			dest := filepath.Join(t.TempDir(), "project")
+			opts := tt.opts
+			opts.Lock = true
+			cloneTestRepo(t, repo, dest, opts)
+
+			if tt.gitRepo {
+				runGit(t, dest, "init", "-q")
+			}
+			editTestTree(t, dest, tt.local)
+
+			result, err := New(Options{NonInteractive: true}).Diff(dest, "")
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			got := make(map[string]string)
+			for _, e := range result.Entries {
+				got[e.Path] = e.Status
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
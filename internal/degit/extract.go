@@ -2,55 +2,388 @@ package degit
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"bytes"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
 )
 
 // ExtractOptions configures the extraction behavior
 type ExtractOptions struct {
-	StripComponents int    // Number of leading path components to strip
-	Subdir          string // Subdirectory to extract (empty for all)
+	StripComponents int       // Number of leading path components to strip
+	Subdir          string    // Subdirectory to extract (empty for all)
+	Include         []string  // Doublestar globs of files to extract (empty for all)
+	Exclude         []string  // Doublestar globs of files to skip
+	Mappings        []Mapping // Subdirectories to extract to separate paths (overrides Subdir)
 }
 
-// ExtractTarball extracts a .tar.gz file to the destination directory
-func ExtractTarball(tarballPath string, destDir string, opts ExtractOptions) error {
-	// Open the tarball
-	file, err := os.Open(tarballPath)
+// Archive formats recognized by DetectArchiveFormat
+const (
+	FormatGzip = "gzip" // gzip-compressed tar
+	FormatZstd = "zstd" // Zstandard-compressed tar
+	FormatXz   = "xz"   // xz-compressed tar
+	FormatZip  = "zip"
+)
+
+// archiveMagic maps the leading bytes of an archive to its format
+var archiveMagic = []struct {
+	magic  []byte
+	format string
+}{
+	{[]byte{0x1f, 0x8b}, FormatGzip},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, FormatZstd},
+	{[]byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, FormatXz},
+	{[]byte("PK\x03\x04"), FormatZip},
+	{[]byte("PK\x05\x06"), FormatZip}, // Empty zip archive
+}
+
+// DetectArchiveFormat identifies an archive by its magic bytes rather than
+// its file extension
+func DetectArchiveFormat(archivePath string) (string, error) {
+	file, err := os.Open(archivePath)
 	if err != nil {
-		return fmt.Errorf("failed to open tarball: %w", err)
+		return "", fmt.Errorf("failed to open archive: %w", err)
 	}
 	defer func() { _ = file.Close() }()
 
-	// Create gzip reader
-	gzReader, err := gzip.NewReader(file)
+	header := make([]byte, 8)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read archive header: %w", err)
+	}
+	header = header[:n]
+
+	for _, m := range archiveMagic {
+		if bytes.HasPrefix(header, m.magic) {
+			return m.format, nil
+		}
+	}
+	return "", fmt.Errorf("unrecognized archive format: %s", filepath.Base(archivePath))
+}
+
+// ExtractArchive extracts a downloaded archive to the destination directory,
+// picking the format from the file contents
+func ExtractArchive(archivePath string, destDir string, opts ExtractOptions) error {
+	ex, err := newExtractor(destDir, opts)
 	if err != nil {
-		return fmt.Errorf("failed to create gzip reader: %w", err)
+		return err
+	}
+	if err := ex.prepareDest(); err != nil {
+		return err
 	}
-	defer func() { _ = gzReader.Close() }()
+	return ex.walkArchive(archivePath)
+}
 
-	// Create tar reader
-	tarReader := tar.NewReader(gzReader)
+// archiveEntry describes one file, directory or symlink of an archive
+// independently of the archive format
+type archiveEntry struct {
+	Name     string // Path inside the archive
+	Type     byte   // tar.TypeDir, tar.TypeReg or tar.TypeSymlink
+	Mode     int64  // Permission bits
+	Linkname string // Symlink target
+}
+
+// Mapping extracts one subdirectory of an archive to a path below the
+// destination directory
+type Mapping struct {
+	Subdir string `json:"subdir" yaml:"subdir"` // Subdirectory in the archive (empty for the root)
+	Dest   string `json:"dest" yaml:"dest"`     // Relative destination path ("." for the destination itself)
+}
 
-	// Calculate strip level
-	stripLevel := opts.StripComponents
-	if stripLevel == 0 {
-		stripLevel = 1 // Default: strip root directory
+// ParseMapping parses a "subdir:dest" mapping; without ":" the subdirectory
+// is extracted to the destination itself
+func ParseMapping(value string) (Mapping, error) {
+	subdir, dest, found := strings.Cut(value, ":")
+	if !found || dest == "" {
+		dest = "."
 	}
+	subdir = strings.Trim(filepath.ToSlash(subdir), "/")
+	if subdir == "." {
+		subdir = ""
+	}
+	if filepath.IsAbs(dest) {
+		return Mapping{}, fmt.Errorf("mapping destination must be relative: %s", value)
+	}
+	return Mapping{Subdir: subdir, Dest: dest}, nil
+}
 
-	// Add subdirectory depth to strip level
-	if opts.Subdir != "" {
-		subdir := strings.Trim(opts.Subdir, "/")
-		stripLevel += strings.Count(subdir, "/") + 1
+// extractTarget is one subdirectory-to-directory pairing of an extractor
+type extractTarget struct {
+	subdir     string
+	destDir    string
+	stripLevel int
+}
+
+// extractor writes archive entries to a destination directory, applying
+// the Subdir filter or mappings, component stripping and path traversal checks
+type extractor struct {
+	destDir   string
+	rootLevel int
+	targets   []extractTarget
+	include   []string
+	exclude   []string
+	plan      *Plan  // Records entries instead of writing them when set
+	conflict  string // Conflict policy reported in the plan
+}
+
+// newExtractor prepares an extractor for a destination directory
+func newExtractor(destDir string, opts ExtractOptions) (*extractor, error) {
+	ex := &extractor{
+		destDir:   destDir,
+		rootLevel: opts.StripComponents,
+		include:   opts.Include,
+		exclude:   opts.Exclude,
+	}
+
+	if err := validatePatterns(ex.include); err != nil {
+		return nil, err
+	}
+	if err := validatePatterns(ex.exclude); err != nil {
+		return nil, err
+	}
+
+	mappings := opts.Mappings
+	if len(mappings) == 0 {
+		mappings = []Mapping{{Subdir: opts.Subdir, Dest: "."}}
+	}
+
+	for _, m := range mappings {
+		target := extractTarget{
+			subdir:     strings.Trim(m.Subdir, "/"),
+			destDir:    filepath.Join(destDir, m.Dest),
+			stripLevel: opts.StripComponents,
+		}
+
+		// Security check: mapped destinations must stay inside destDir
+		if !hasPrefix(target.destDir, destDir) {
+			return nil, fmt.Errorf("mapping destination escapes %s: %s", destDir, m.Dest)
+		}
+
+		// Add subdirectory depth to strip level
+		if target.subdir != "" {
+			target.stripLevel += strings.Count(target.subdir, "/") + 1
+		}
+
+		ex.targets = append(ex.targets, target)
 	}
 
-	// Ensure destination directory exists
-	if err := os.MkdirAll(destDir, 0755); err != nil {
+	return ex, nil
+}
+
+// prepareDest ensures the destination directory exists
+func (ex *extractor) prepareDest() error {
+	if err := os.MkdirAll(ex.destDir, 0755); err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
+	return nil
+}
+
+// walkArchive feeds every entry of an archive to the extractor, picking the
+// format from the file contents
+func (ex *extractor) walkArchive(archivePath string) error {
+	format, err := DetectArchiveFormat(archivePath)
+	if err != nil {
+		return err
+	}
+	if format == FormatZip {
+		return ex.walkZip(archivePath)
+	}
+	return ex.walkTarball(archivePath)
+}
+
+// extract writes a single entry to every target it belongs to; reader
+// supplies the contents of regular files
+func (ex *extractor) extract(entry archiveEntry, reader io.Reader) error {
+	entry.Name = cleanEntryName(entry.Name)
+	if entry.Name == "" {
+		return nil // The archive root itself ("./")
+	}
+	if entry.Name == ".." || strings.HasPrefix(entry.Name, "../") {
+		return fmt.Errorf("path traversal detected: %s", entry.Name)
+	}
+
+	var matched []extractTarget
+	for _, target := range ex.targets {
+		// Skip if file doesn't match subdirectory filter
+		if target.subdir != "" && !containsSubdir(entry.Name, target.subdir, ex.rootLevel) {
+			continue
+		}
+		matched = append(matched, target)
+	}
+
+	// Contents can only be streamed once; buffer them for overlapping mappings
+	if len(matched) > 1 && entry.Type == tar.TypeReg {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read entry %s: %w", entry.Name, err)
+		}
+		for _, target := range matched {
+			if err := ex.extractTo(target, entry, bytes.NewReader(data)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, target := range matched {
+		if err := ex.extractTo(target, entry, reader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractTo writes a single entry below a target's destination directory
+func (ex *extractor) extractTo(target extractTarget, entry archiveEntry, reader io.Reader) error {
+	// Strip leading path components
+	name := stripPath(entry.Name, target.stripLevel)
+	if name == "" {
+		return nil
+	}
+
+	// Apply include/exclude filters; directories are only created when
+	// selected, otherwise they come into existence with their files
+	rel := filepath.ToSlash(name)
+	if entry.Type == tar.TypeDir {
+		if matchesAny(rel, ex.exclude) || (len(ex.include) > 0 && !matchesAny(rel, ex.include)) {
+			return nil
+		}
+	} else if !matchesFilters(rel, ex.include, ex.exclude) {
+		if ex.plan != nil {
+			return ex.planEntry(entry, filepath.Join(target.destDir, name), reader, PlanSkip)
+		}
+		return nil
+	}
+
+	// Construct full destination path
+	destPath := filepath.Join(target.destDir, name)
+
+	// Security check: prevent path traversal
+	if !strings.HasPrefix(filepath.Clean(destPath), filepath.Clean(target.destDir)) {
+		return fmt.Errorf("path traversal detected: %s", entry.Name)
+	}
+
+	if ex.plan != nil {
+		return ex.planEntry(entry, destPath, reader, "")
+	}
+
+	switch entry.Type {
+	case tar.TypeDir:
+		if err := os.MkdirAll(destPath, os.FileMode(entry.Mode)); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+
+	case tar.TypeReg:
+		if err := extractFile(reader, destPath, entry.Mode); err != nil {
+			return fmt.Errorf("failed to extract file: %w", err)
+		}
+
+	case tar.TypeSymlink:
+		// Security check: ensure symlink target doesn't escape destination
+		linkTarget := entry.Linkname
+		if filepath.IsAbs(linkTarget) {
+			return nil // Skip absolute symlinks for security
+		}
+		targetPath := filepath.Join(filepath.Dir(destPath), linkTarget)
+		if !strings.HasPrefix(filepath.Clean(targetPath), filepath.Clean(ex.destDir)) {
+			return nil // Skip symlinks that escape destination
+		}
+		// Ignore symlink errors (Windows compatibility)
+		_ = os.Symlink(linkTarget, destPath)
+	}
+
+	return nil
+}
+
+// openTarball opens a compressed tar file, sniffing the compression format
+// (gzip, zstd or xz) from its magic bytes
+func openTarball(tarballPath string) (*tar.Reader, io.Closer, error) {
+	format, err := DetectArchiveFormat(tarballPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(tarballPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open tarball: %w", err)
+	}
+
+	decompressed, err := newDecompressor(format, file)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+
+	closer := closerFunc(func() error {
+		_ = decompressed.Close()
+		return file.Close()
+	})
+	return tar.NewReader(decompressed), closer, nil
+}
+
+// newDecompressor wraps r with a decompressor for the given tarball format
+func newDecompressor(format string, r io.Reader) (io.ReadCloser, error) {
+	switch format {
+	case FormatGzip:
+		gzReader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		return gzReader, nil
+	case FormatZstd:
+		zstdReader, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd reader: %w", err)
+		}
+		return zstdReader.IOReadCloser(), nil
+	case FormatXz:
+		xzReader, err := xz.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create xz reader: %w", err)
+		}
+		return io.NopCloser(xzReader), nil
+	default:
+		return nil, fmt.Errorf("not a compressed tarball (format: %s)", format)
+	}
+}
+
+// closerFunc adapts a function to io.Closer
+type closerFunc func() error
+
+// Close calls the function
+func (f closerFunc) Close() error {
+	return f()
+}
+
+// ExtractTarball extracts a compressed tar file (.tar.gz, .tar.zst or
+// .tar.xz) to the destination directory
+func ExtractTarball(tarballPath string, destDir string, opts ExtractOptions) error {
+	ex, err := newExtractor(destDir, opts)
+	if err != nil {
+		return err
+	}
+	if err := ex.prepareDest(); err != nil {
+		return err
+	}
+	return ex.walkTarball(tarballPath)
+}
+
+// walkTarball feeds every entry of a compressed tar file to the extractor
+func (ex *extractor) walkTarball(tarballPath string) error {
+	// Open the tarball
+	tarReader, closer, err := openTarball(tarballPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closer.Close() }()
 
 	// Extract files
 	for {
@@ -62,87 +395,226 @@ func ExtractTarball(tarballPath string, destDir string, opts ExtractOptions) err
 			return fmt.Errorf("failed to read tar entry: %w", err)
 		}
 
-		// Skip if file doesn't match subdirectory filter
-		if opts.Subdir != "" {
-			subdir := strings.Trim(opts.Subdir, "/")
-			if !containsSubdir(header.Name, subdir) {
-				continue
-			}
+		entry := archiveEntry{
+			Name:     header.Name,
+			Type:     header.Typeflag,
+			Mode:     header.Mode,
+			Linkname: header.Linkname,
+		}
+		if err := ex.extract(entry, tarReader); err != nil {
+			return err
 		}
+	}
 
-		// Strip leading path components
-		name := stripPath(header.Name, stripLevel)
-		if name == "" {
-			continue
+	return nil
+}
+
+// ExtractZip extracts a .zip file to the destination directory with the
+// same semantics as ExtractTarball
+func ExtractZip(zipPath string, destDir string, opts ExtractOptions) error {
+	ex, err := newExtractor(destDir, opts)
+	if err != nil {
+		return err
+	}
+	if err := ex.prepareDest(); err != nil {
+		return err
+	}
+	return ex.walkZip(zipPath)
+}
+
+// walkZip feeds every entry of a .zip file to the extractor
+func (ex *extractor) walkZip(zipPath string) error {
+	zipReader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	defer func() { _ = zipReader.Close() }()
+
+	for _, f := range zipReader.File {
+		if err := extractZipEntry(ex, f); err != nil {
+			return err
 		}
+	}
 
-		// Construct full destination path
-		destPath := filepath.Join(destDir, name)
+	return nil
+}
+
+// extractZipEntry converts a zip entry to an archiveEntry and extracts it
+func extractZipEntry(ex *extractor, f *zip.File) error {
+	entry := zipEntry(f)
 
-		// Security check: prevent path traversal
-		if !strings.HasPrefix(filepath.Clean(destPath), filepath.Clean(destDir)) {
-			return fmt.Errorf("path traversal detected: %s", header.Name)
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read zip entry: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	// Zip archives store the symlink target as the entry contents
+	if entry.Type == tar.TypeSymlink {
+		target, err := io.ReadAll(rc)
+		if err != nil {
+			return fmt.Errorf("failed to read zip entry: %w", err)
 		}
+		entry.Linkname = string(target)
+	}
 
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(destPath, os.FileMode(header.Mode)); err != nil {
-				return fmt.Errorf("failed to create directory: %w", err)
-			}
+	return ex.extract(entry, rc)
+}
 
-		case tar.TypeReg:
-			if err := extractFile(tarReader, destPath, header.Mode); err != nil {
-				return fmt.Errorf("failed to extract file: %w", err)
-			}
+// zipEntry describes a zip file header as an archiveEntry. Archives created
+// on Windows carry no Unix permissions, so defaults are applied.
+func zipEntry(f *zip.File) archiveEntry {
+	mode := f.Mode()
+	entry := archiveEntry{Name: f.Name, Mode: int64(mode.Perm())}
 
-		case tar.TypeSymlink:
-			// Security check: ensure symlink target doesn't escape destination
-			linkTarget := header.Linkname
-			if filepath.IsAbs(linkTarget) {
-				continue // Skip absolute symlinks for security
-			}
-			targetPath := filepath.Join(filepath.Dir(destPath), linkTarget)
-			if !strings.HasPrefix(filepath.Clean(targetPath), filepath.Clean(destDir)) {
-				continue // Skip symlinks that escape destination
-			}
-			if err := os.Symlink(linkTarget, destPath); err != nil {
-				// Ignore symlink errors (Windows compatibility)
-				continue
-			}
+	switch {
+	case mode&os.ModeSymlink != 0:
+		entry.Type = tar.TypeSymlink
+	case mode.IsDir() || strings.HasSuffix(f.Name, "/"):
+		entry.Type = tar.TypeDir
+		if entry.Mode == 0 {
+			entry.Mode = 0755
+		}
+	default:
+		entry.Type = tar.TypeReg
+		if entry.Mode == 0 {
+			entry.Mode = 0644
 		}
 	}
 
-	return nil
+	return entry
+}
+
+// cleanEntryName normalizes the name of an archive entry to a clean slash
+// path without a leading "./" or "/" ("" for the archive root)
+func cleanEntryName(name string) string {
+	name = path.Clean(strings.TrimLeft(filepath.ToSlash(name), "/"))
+	if name == "." {
+		return ""
+	}
+	return name
 }
 
 // stripPath removes the first n path components from a path
-func stripPath(path string, n int) string {
-	parts := strings.Split(filepath.ToSlash(path), "/")
+func stripPath(name string, n int) string {
+	parts := strings.Split(cleanEntryName(name), "/")
 	if len(parts) <= n {
 		return ""
 	}
 	return filepath.Join(parts[n:]...)
 }
 
-// containsSubdir checks if a path contains a specific subdirectory
-func containsSubdir(path string, subdir string) bool {
+// containsSubdir checks if a path contains a specific subdirectory below
+// the first rootLevel components (the archive root directory)
+func containsSubdir(name string, subdir string, rootLevel int) bool {
 	// Normalize paths
-	path = filepath.ToSlash(path)
-	subdir = filepath.ToSlash(strings.Trim(subdir, "/"))
+	name = cleanEntryName(name)
+	subdir = cleanEntryName(subdir)
 
 	// Split into parts
-	parts := strings.Split(path, "/")
-	if len(parts) < 2 {
+	parts := strings.Split(name, "/")
+	if len(parts) < rootLevel+1 {
 		return false
 	}
 
 	// Skip the root directory (repo-name-hash)
-	pathAfterRoot := strings.Join(parts[1:], "/")
+	pathAfterRoot := strings.Join(parts[rootLevel:], "/")
 
 	// Check if path starts with subdir
 	return strings.HasPrefix(pathAfterRoot, subdir+"/") || pathAfterRoot == subdir
 }
 
+// DetectStripComponents returns 1 if every file of an archive lives under
+// a single top-level directory (as in GitHub-style archives), 0 otherwise
+func DetectStripComponents(archivePath string) (int, error) {
+	format, err := DetectArchiveFormat(archivePath)
+	if err != nil {
+		return 0, err
+	}
+
+	var names []string
+	if format == FormatZip {
+		names, err = listZipFiles(archivePath)
+	} else {
+		names, err = listTarFiles(archivePath)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return commonRootLevel(names), nil
+}
+
+// listTarFiles returns the names of the non-directory entries of a compressed tar file
+func listTarFiles(tarballPath string) ([]string, error) {
+	tarReader, closer, err := openTarball(tarballPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = closer.Close() }()
+
+	var names []string
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry: %w", err)
+		}
+		// Only files matter: directory entries may be listed without a trailing slash
+		if header.Typeflag == tar.TypeDir || header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		if name := cleanEntryName(header.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// listZipFiles returns the names of the non-directory entries of a .zip file
+func listZipFiles(zipPath string) ([]string, error) {
+	zipReader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	defer func() { _ = zipReader.Close() }()
+
+	var names []string
+	for _, f := range zipReader.File {
+		if zipEntry(f).Type == tar.TypeDir {
+			continue
+		}
+		if name := cleanEntryName(f.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// commonRootLevel returns 1 if all file names share one top-level directory, 0 otherwise
+func commonRootLevel(names []string) int {
+	root := ""
+	for _, name := range names {
+		first, _, found := strings.Cut(cleanEntryName(name), "/")
+		if !found {
+			return 0 // File at the top level, no root directory
+		}
+		if root == "" {
+			root = first
+		} else if first != root {
+			return 0
+		}
+	}
+	if root == "" {
+		return 0
+	}
+	return 1
+}
+
 // extractFile extracts a single file from the tar reader
 func extractFile(reader io.Reader, destPath string, mode int64) error {
 	// Ensure parent directory exists
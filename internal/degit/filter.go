@@ -0,0 +1,85 @@
+package degit
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// validatePatterns checks that every include/exclude pattern is a valid glob
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid glob pattern: %s", pattern)
+		}
+	}
+	return nil
+}
+
+// matchesAny reports whether a slash-separated path, or any of its parent
+// directories, matches one of the patterns. Matching parents lets "docs"
+// select everything below docs/ without having to write "docs/**".
+func matchesAny(name string, patterns []string) bool {
+	for p := name; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		for _, pattern := range patterns {
+			if ok, _ := doublestar.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesFilters reports whether a file passes the include and exclude
+// filters: it must match an include pattern (if any) and no exclude pattern
+func matchesFilters(name string, include, exclude []string) bool {
+	if len(exclude) > 0 && matchesAny(name, exclude) {
+		return false
+	}
+	if len(include) > 0 && !matchesAny(name, include) {
+		return false
+	}
+	return true
+}
+
+// pruneFiltered removes files from dir that do not pass the include and
+// exclude filters, along with directories left empty. It is used where
+// files cannot be filtered before being written (git mode).
+func pruneFiltered(dir string, include, exclude []string) error {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+
+	var dirs []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if info.IsDir() {
+			dirs = append(dirs, p)
+			return nil
+		}
+		if !matchesFilters(filepath.ToSlash(rel), include, exclude) {
+			return os.Remove(p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Remove directories emptied by filtering, deepest first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if entries, err := os.ReadDir(dirs[i]); err == nil && len(entries) == 0 {
+			_ = os.Remove(dirs[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package degit
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFiltersMatchInBothModes(t *testing.T) {
+	files := map[string]string{
+		"README.md":                    "readme\n",
+		"src/main.go":                  "package main\n",
+		"shared/ci/README.md":          "ci\n",
+		"shared/ci/workflows/test.yml": "on: push\n",
+		"shared/lint/golangci.yml":     "linters: {}\n",
+		"shared/lint/docs/rules.md":    "rules\n",
+	}
+
+	tests := []struct {
+		name     string
+		subdir   string
+		include  []string
+		exclude  []string
+		mappings []Mapping
+		want     map[string]string
+	}{
+		{
+			name:    "exclude at the root",
+			exclude: []string{"shared"},
+			want:    map[string]string{"README.md": "readme\n", "src/main.go": "package main\n"},
+		},
+		{
+			name:    "include below a subdirectory",
+			subdir:  "/shared",
+			include: []string{"ci/**"},
+			want:    map[string]string{"ci/README.md": "ci\n", "ci/workflows/test.yml": "on: push\n"},
+		},
+		{
+			name:     "include below a mapping",
+			include:  []string{"workflows/**"},
+			mappings: []Mapping{{Subdir: "shared/ci", Dest: ".github"}},
+			want:     map[string]string{".github/workflows/test.yml": "on: push\n"},
+		},
+		{
+			name:     "exclude below several mappings",
+			exclude:  []string{"docs", "README.md"},
+			mappings: []Mapping{{Subdir: "shared/ci", Dest: "ci"}, {Subdir: "shared/lint", Dest: "."}},
+			want:     map[string]string{"ci/workflows/test.yml": "on: push\n", "golangci.yml": "linters: {}\n"},
+		},
+		{
+			name:     "overlapping mappings",
+			include:  []string{"workflows/**", "lint/*.yml"},
+			mappings: []Mapping{{Subdir: "shared", Dest: "all"}, {Subdir: "shared/ci", Dest: ".github"}},
+			want:     map[string]string{"all/lint/golangci.yml": "linters: {}\n", ".github/workflows/test.yml": "on: push\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			// git mode clones <URL>.git
+			url := newTestRepo(t, files)
+			repo := url + ".git"
+			if err := os.Rename(url, repo); err != nil {
+				t.Fatal(err)
+			}
+			opts := Options{Include: tt.include, Exclude: tt.exclude, Mappings: tt.mappings}
+
+			local, err := ParseSource(repo + tt.subdir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tarDest := filepath.Join(t.TempDir(), "tar")
+			if _, err := New(opts).cloneWithTar(local, tarDest, t.TempDir()); err != nil {
+				t.Fatalf("tar mode: %v", err)
+			}
+
+			remote := &Source{Site: "github", Owner: "acme", Repo: "tpl", Ref: "HEAD", Subdir: tt.subdir, URL: "file://" + url}
+			gitDest := filepath.Join(t.TempDir(), "git")
+			if _, err := New(opts).cloneWithGit(remote, gitDest); err != nil {
+				t.Fatalf("git mode: %v", err)
+			}
+
+			if got := readTestTree(t, tarDest); !maps.Equal(got, tt.want) {
+				t.Errorf("tar mode = %q, want %q", got, tt.want)
+			}
+			if got := readTestTree(t, gitDest); !maps.Equal(got, tt.want) {
+				t.Errorf("git mode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package degit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// copyDir recursively copies the contents of srcDir into destDir, keeping
+// file modes and relative symlinks
+func copyDir(srcDir string, destDir string) error {
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destDir, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			_ = os.Remove(target)
+			// Ignore symlink errors (Windows compatibility)
+			_ = os.Symlink(link, target)
+			return nil
+
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+
+		default:
+			return nil
+		}
+	})
+}
+
+// copyFile copies a single file, creating parent directories as needed
+func copyFile(srcPath string, destPath string, mode os.FileMode) error {
+	in, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %s: %w", srcPath, err)
+	}
+	return out.Close()
+}
+
+// newStagingDir creates an empty staging directory next to dest, on the
+// same filesystem so it can be renamed into place
+func newStagingDir(dest string) (string, error) {
+	parent := filepath.Dir(filepath.Clean(dest))
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	staging, err := os.MkdirTemp(parent, "."+filepath.Base(dest)+".degit-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	// MkdirTemp uses 0700; the staging directory may become dest itself
+	if err := os.Chmod(staging, 0755); err != nil {
+		_ = os.RemoveAll(staging)
+		return "", fmt.Errorf("failed to prepare staging directory: %w", err)
+	}
+
+	return staging, nil
+}
+
+// commitStaging moves the staged files into dest. A missing or empty dest is
+// replaced by a single rename; otherwise the staged tree is merged into it,
+// overwriting files that exist in both.
+func commitStaging(staging string, dest string) error {
+	entries, err := os.ReadDir(dest)
+	if os.IsNotExist(err) {
+		return os.Rename(staging, dest)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		if err := os.Remove(dest); err == nil {
+			return os.Rename(staging, dest)
+		}
+	}
+
+	return mergeDir(staging, dest)
+}
+
+// mergeDir moves every entry of srcDir into destDir, descending into
+// directories present on both sides and replacing everything else
+func mergeDir(srcDir string, destDir string) error {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		srcPath := filepath.Join(srcDir, entry.Name())
+		destPath := filepath.Join(destDir, entry.Name())
+
+		destInfo, err := os.Lstat(destPath)
+		if err == nil && destInfo.IsDir() && entry.IsDir() {
+			if err := mergeDir(srcPath, destPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if err == nil {
+			if err := os.RemoveAll(destPath); err != nil {
+				return err
+			}
+		}
+
+		if err := os.Rename(srcPath, destPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package degit
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// DefaultMaxDepth is how deeply clone actions may nest by default
+const DefaultMaxDepth = 10
+
+// SourceNode is a source composing a scaffold, with the sources its clone
+// actions pulled in
+type SourceNode struct {
+	Source    string        // Source without the ref (see Source.Spec)
+	Ref       string        // Ref as requested
+	Hash      string        // Resolved commit hash
+	Dest      string        // Directory the source was cloned into, relative to the scaffold root
+	Duplicate bool          // Already cloned elsewhere in the scaffold, so skipped
+	Children  []*SourceNode // Sources of clone actions, in order
+}
+
+// cloneGraph records the sources cloned for one scaffold
+type cloneGraph struct {
+	root *SourceNode
+	seen map[string]bool // Keys of the clones done so far (see cloneKey)
+}
+
+// sourceKey identifies a source at a ref
+func sourceKey(src *Source) string {
+	return src.Spec() + "#" + src.Ref
+}
+
+// cloneKey identifies a clone of a source into a directory (relative to
+// the scaffold root) with the given filters
+func cloneKey(src *Source, dir string, include []string, exclude []string) string {
+	return fmt.Sprintf("%s %s %q %q", sourceKey(src), path.Clean(dir), include, exclude)
+}
+
+// Graph returns the tree of sources composing the last clone, or nil
+func (d *Degit) Graph() *SourceNode {
+	if d.graph == nil {
+		return nil
+	}
+	return d.graph.root
+}
+
+// enterSource records src, fetched at hash, in the clone graph. A clone
+// that was not started by a clone action starts a new graph.
+func (d *Degit) enterSource(src *Source, hash string) {
+	if d.parent == nil {
+		d.graph = &cloneGraph{seen: make(map[string]bool)}
+		d.path = nil
+	}
+
+	d.graph.seen[cloneKey(src, d.dir, d.options.Include, d.options.Exclude)] = true
+	d.path = append(d.path, sourceKey(src))
+
+	d.node = &SourceNode{Source: src.Spec(), Ref: src.Ref, Hash: hash, Dest: path.Clean(d.dir)}
+	if d.parent != nil {
+		d.parent.Children = append(d.parent.Children, d.node)
+	} else {
+		d.graph.root = d.node
+	}
+}
+
+// checkNested checks that src can be cloned into dir by a clone action of
+// the current source. It reports false for sources that were already
+// cloned there with the same filters.
+func (d *Degit) checkNested(src *Source, dir string, include []string, exclude []string) (bool, error) {
+	key := sourceKey(src)
+
+	if i := slices.Index(d.path, key); i >= 0 {
+		cycle := append(slices.Clone(d.path[i:]), key)
+		for j, k := range cycle {
+			cycle[j] = strings.TrimSuffix(k, "#HEAD")
+		}
+		return false, fmt.Errorf("clone actions form a cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	maxDepth := d.options.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if len(d.path) > maxDepth {
+		return false, fmt.Errorf("clone actions nested deeper than %d levels", maxDepth)
+	}
+
+	if d.graph != nil && d.graph.seen[cloneKey(src, dir, include, exclude)] {
+		d.node.Children = append(d.node.Children, &SourceNode{Source: src.Spec(), Ref: src.Ref, Dest: path.Clean(dir), Duplicate: true})
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// nest makes nested clone into dir (relative to the scaffold root) as
+// part of the current source's graph
+func (d *Degit) nest(nested *Degit, dir string) {
+	nested.graph = d.graph
+	nested.parent = d.node
+	nested.path = slices.Clone(d.path)
+	nested.dir = dir
+}
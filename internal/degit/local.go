@@ -0,0 +1,141 @@
+package degit
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// isLocalSource reports whether a source string refers to a local path:
+// file:// URLs, absolute paths and paths starting with "./" or "../"
+func isLocalSource(src string) bool {
+	return strings.HasPrefix(src, "file://") ||
+		strings.HasPrefix(src, "./") ||
+		strings.HasPrefix(src, "../") ||
+		src == "." ||
+		filepath.IsAbs(src)
+}
+
+// parseLocalSource parses a local path or file:// URL (optionally with #ref)
+// into a Source. The path may point anywhere inside a git working tree; the
+// part below the repository root becomes the Subdir.
+func parseLocalSource(src string) (*Source, error) {
+	path := strings.TrimPrefix(src, "file://")
+	ref := "HEAD"
+	if i := strings.LastIndex(path, "#"); i >= 0 {
+		if path[i+1:] != "" {
+			ref = path[i+1:]
+		}
+		path = path[:i]
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %s: %w", path, err)
+	}
+	// Resolve symlinks so the path can be compared with the git toplevel
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("local source %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("local source %s is not a directory", path)
+	}
+
+	out, err := exec.Command("git", "-C", absPath, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("local source %s is not inside a git repository", path)
+	}
+	root := filepath.Clean(strings.TrimSpace(string(out)))
+
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve %s relative to %s: %w", absPath, root, err)
+	}
+	subdir := ""
+	if rel != "." {
+		subdir = "/" + filepath.ToSlash(rel)
+	}
+
+	return &Source{
+		Site:   "local",
+		Repo:   filepath.Base(absPath),
+		Ref:    ref,
+		Subdir: subdir,
+		URL:    "file://" + filepath.ToSlash(root),
+		Path:   root,
+	}, nil
+}
+
+// resolveLocalRef resolves any revision git understands (branch, tag,
+// abbreviated hash, HEAD~1, ...) to a full commit hash
+func resolveLocalRef(repoPath string, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("could not resolve reference: %s", ref)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// LocalProvider implements Provider for repositories on the local disk.
+// It never touches the network: refs come from git ls-remote on the path
+// and archives are produced with git archive.
+type LocalProvider struct{}
+
+// Name returns the site key
+func (p *LocalProvider) Name() string {
+	return "local"
+}
+
+// BaseURL returns the file URL scheme root
+func (p *LocalProvider) BaseURL() string {
+	return "file://"
+}
+
+// FetchRefs lists the refs of the local repository
+func (p *LocalProvider) FetchRefs(src *Source) ([]Ref, error) {
+	return FetchRefs(src.Path)
+}
+
+// ArchiveURL returns a descriptive file URL; archives are built locally
+func (p *LocalProvider) ArchiveURL(src *Source, hash string) string {
+	return fmt.Sprintf("%s#%s", src.URL, hash)
+}
+
+// Authenticate is a no-op for local repositories
+func (p *LocalProvider) Authenticate(req *http.Request) {}
+
+// Download writes a tarball of the commit to destPath using git archive.
+// Entries are prefixed with the repository name so the usual
+// StripComponents of 1 applies.
+func (p *LocalProvider) Download(src *Source, hash string, destPath string, opts DownloadOptions) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	prefix := filepath.Base(src.Path) + "/"
+	cmd := exec.Command("git", "-C", src.Path, "archive", "--format=tar.gz", "--prefix="+prefix, "-o", destPath, hash)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git archive failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CheckAccess reports whether the repository path exists
+func (p *LocalProvider) CheckAccess(src *Source) (bool, error) {
+	_, err := os.Stat(src.Path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
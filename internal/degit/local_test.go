@@ -0,0 +1,231 @@
+package degit
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLocalSource(t *testing.T) {
+	repo := newTestRepo(t, map[string]string{"pkg/lib/lib.go": "package lib\n"})
+	root, err := filepath.EvalSymlinks(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Dir(repo))
+
+	tests := []struct {
+		name    string
+		spec    string
+		ref     string
+		subdir  string
+		wantErr bool
+	}{
+		{name: "relative path", spec: "./" + filepath.Base(repo), ref: "HEAD"},
+		{name: "absolute path", spec: repo, ref: "HEAD"},
+		{name: "file URL with ref", spec: "file://" + repo + "#v1", ref: "v1"},
+		{name: "subdirectory", spec: repo + "/pkg/lib", ref: "HEAD", subdir: "/pkg/lib"},
+		{name: "empty ref", spec: repo + "#", ref: "HEAD"},
+		{name: "missing path", spec: repo + "/missing", wantErr: true},
+		{name: "file", spec: repo + "/pkg/lib/lib.go", wantErr: true},
+		{name: "not a repository", spec: t.TempDir(), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := ParseSource(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSource(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !src.IsLocal() || src.Site != "local" {
+				t.Errorf("ParseSource(%q) = %+v, want a local source", tt.spec, src)
+			}
+			if src.Path != root || src.Ref != tt.ref || src.Subdir != tt.subdir {
+				t.Errorf("ParseSource(%q) = path %s, ref %s, subdir %q; want %s, %s, %q", tt.spec, src.Path, src.Ref, src.Subdir, root, tt.ref, tt.subdir)
+			}
+		})
+	}
+}
+
+func TestCloneLocal(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string // Files of the first commit, tagged v1
+		next    map[string]string // Files changed by a second commit
+		spec    string            // Source, relative to the repository ("" for the repository itself)
+		opts    Options
+		dest    map[string]string // Files in dest before the clone
+		want    map[string]string // Files in dest after the clone (without the lock)
+		wantErr bool
+	}{
+		{
+			name:  "relative path",
+			files: map[string]string{"README.md": "hello\n", "src/main.go": "package main\n"},
+			spec:  "./",
+			want:  map[string]string{"README.md": "hello\n", "src/main.go": "package main\n"},
+		},
+		{
+			name:  "file URL at a tag",
+			files: map[string]string{"README.md": "v1\n"},
+			next:  map[string]string{"README.md": "v2\n", "NEW.md": "new\n"},
+			spec:  "file://#v1",
+			want:  map[string]string{"README.md": "v1\n"},
+		},
+		{
+			name:  "latest commit",
+			files: map[string]string{"README.md": "v1\n"},
+			next:  map[string]string{"README.md": "v2\n", "NEW.md": "new\n"},
+			want:  map[string]string{"README.md": "v2\n", "NEW.md": "new\n"},
+		},
+		{
+			name:  "subdirectory",
+			files: map[string]string{"README.md": "root\n", "templates/go/main.go": "package main\n", "templates/go/go.mod": "module x\n"},
+			spec:  "/templates/go",
+			want:  map[string]string{"main.go": "package main\n", "go.mod": "module x\n"},
+		},
+		{
+			name: "manifest actions and variables",
+			files: map[string]string{
+				"degit.json": `{
+  "variables": [{"name": "name"}, {"name": "greeting", "default": "hello"}],
+  "actions": [
+    {"action": "remove", "files": ["LICENSE"]},
+    {"action": "rename", "from": "gitignore", "to": ".gitignore"}
+  ]
+}`,
+				"LICENSE":                "MIT\n",
+				"gitignore":              "bin/\n",
+				"__NAME__/{{name}}.go":   "package {{name}}\n// {{ greeting }}, __NAME__\n",
+				"__NAME__/logo.bin":      "{{name}}\x00",
+				"cmd/__NAME__/main.go":   "package main\n",
+				"cmd/__NAME__/README.md": "# {{name}}\n",
+			},
+			opts: Options{Vars: map[string]string{"name": "app"}},
+			want: map[string]string{
+				".gitignore":        "bin/\n",
+				"app/app.go":        "package app\n// hello, app\n",
+				"app/logo.bin":      "{{name}}\x00",
+				"cmd/app/main.go":   "package main\n",
+				"cmd/app/README.md": "# app\n",
+			},
+		},
+		{
+			name:  "merge into existing files",
+			files: map[string]string{"a.txt": "new\n", "b.txt": "b\n"},
+			opts:  Options{Conflict: ConflictSkip},
+			dest:  map[string]string{"a.txt": "mine\n", "c.txt": "c\n"},
+			want:  map[string]string{"a.txt": "mine\n", "b.txt": "b\n", "c.txt": "c\n"},
+		},
+		{
+			name: "failed action leaves dest untouched",
+			files: map[string]string{
+				"degit.json": `[{"action": "remove", "files": ["a.txt"]}, {"action": "clone", "src": "./missing"}]`,
+				"a.txt":      "new\n",
+				"b.txt":      "b\n",
+			},
+			opts:    Options{Conflict: ConflictOverwrite},
+			dest:    map[string]string{"a.txt": "mine\n"},
+			want:    map[string]string{"a.txt": "mine\n"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			repo := newTestRepo(t, tt.files)
+			runGit(t, repo, "tag", "v1")
+			if tt.next != nil {
+				commitTestFiles(t, repo, tt.next)
+			}
+			t.Chdir(repo)
+
+			spec := repo + tt.spec
+			switch {
+			case strings.HasPrefix(tt.spec, "./"):
+				spec = tt.spec
+			case strings.HasPrefix(tt.spec, "file://"):
+				spec = "file://" + repo + strings.TrimPrefix(tt.spec, "file://")
+			}
+			src, err := ParseSource(spec)
+			if err != nil {
+				t.Fatalf("ParseSource(%q) error = %v", spec, err)
+			}
+
+			parent := t.TempDir()
+			dest := filepath.Join(parent, "project")
+			if tt.dest != nil {
+				writeTestTree(t, dest, tt.dest)
+			}
+
+			opts := tt.opts
+			opts.NonInteractive = true
+			opts.Lock = true
+			err = New(opts).Clone(src, dest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Clone(%q) error = %v, wantErr %v", spec, err, tt.wantErr)
+			}
+
+			got := readTestTree(t, dest)
+			delete(got, LockFileName)
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("dest = %q, want %q", got, tt.want)
+			}
+
+			// The staging directory never outlives the clone
+			if entries, err := os.ReadDir(parent); err != nil || len(entries) != 1 {
+				t.Errorf("%s holds %d entries after the clone, want only the project", parent, len(entries))
+			}
+
+			lock, err := ReadLock(dest)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("lock written by a failed clone")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkTestLock(t, lock, repo, dest, tt.dest)
+		})
+	}
+}
+
+// checkTestLock checks that a lock points at the commit of its ref and
+// records the checksum of every file the clone wrote, and nothing else
+func checkTestLock(t *testing.T, lock *Lock, repo string, dest string, before map[string]string) {
+	t.Helper()
+	if lock.Site != "local" {
+		t.Errorf("lock site = %s, want local", lock.Site)
+	}
+	if want := runGit(t, repo, "rev-parse", lock.Ref+"^{commit}"); lock.Hash != want {
+		t.Errorf("lock hash = %s, want %s (%s)", lock.Hash, want, lock.Ref)
+	}
+
+	files, err := checksumFiles(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := readTestTree(t, dest)
+	for name, sum := range files {
+		if content, existed := before[name]; existed && contents[name] == content {
+			// Left as it was (skipped, or not part of the template)
+			if _, ok := lock.Files[name]; ok {
+				t.Errorf("lock records %s, which the clone did not write", name)
+			}
+			continue
+		}
+		if lock.Files[name] != sum {
+			t.Errorf("lock checksum of %s = %q, want %q", name, lock.Files[name], sum)
+		}
+	}
+	if len(lock.Files) > len(files) {
+		t.Errorf("lock records %d files, dest has %d", len(lock.Files), len(files))
+	}
+}
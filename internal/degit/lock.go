@@ -0,0 +1,189 @@
+package degit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is the file recording where a project was scaffolded from
+const LockFileName = ".degit.lock"
+
+// Lock file formats
+const (
+	LockJSON = "json"
+	LockYAML = "yaml"
+)
+
+// Lock records where a project was scaffolded from
+type Lock struct {
+	Source    string            `json:"source" yaml:"source"`                           // Source without the ref (see Source.Spec)
+	Site      string            `json:"site" yaml:"site"`                               // e.g., "github", "local", "url"
+	Owner     string            `json:"owner" yaml:"owner"`                             // Repository owner (host for archive URLs)
+	Repo      string            `json:"repo" yaml:"repo"`                               // Repository name
+	Ref       string            `json:"ref" yaml:"ref"`                                 // Ref as requested
+	Hash      string            `json:"hash" yaml:"hash"`                               // Resolved commit hash
+	Subdir    string            `json:"subdir,omitempty" yaml:"subdir,omitempty"`       // Subdirectory of the source
+	Include   []string          `json:"include,omitempty" yaml:"include,omitempty"`     // Include filters used for extraction
+	Exclude   []string          `json:"exclude,omitempty" yaml:"exclude,omitempty"`     // Exclude filters used for extraction
+	Mappings  []Mapping         `json:"mappings,omitempty" yaml:"mappings,omitempty"`   // Subdirectory mappings used for extraction
+	Variables map[string]string `json:"variables,omitempty" yaml:"variables,omitempty"` // Template variable values
+	Version   string            `json:"version,omitempty" yaml:"version,omitempty"`     // Version of the tool that wrote the lock
+	Files     map[string]string `json:"files,omitempty" yaml:"files,omitempty"`         // SHA-256 of each scaffolded file
+
+	format string // Format the lock was read in
+}
+
+// ValidateLockFormat checks that format is a known lock file format
+func ValidateLockFormat(format string) error {
+	if format != LockJSON && format != LockYAML {
+		return fmt.Errorf("unknown lock format %q (expected %s or %s)", format, LockJSON, LockYAML)
+	}
+	return nil
+}
+
+// newLock returns the lock for a clone of src at hash whose files were
+// written to dir
+func (d *Degit) newLock(src *Source, hash string, dir string) (*Lock, error) {
+	files, err := checksumFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Lock{
+		Source:    src.Spec(),
+		Site:      src.Site,
+		Owner:     src.Owner,
+		Repo:      src.Repo,
+		Ref:       src.Ref,
+		Hash:      hash,
+		Subdir:    src.Subdir,
+		Include:   d.options.Include,
+		Exclude:   d.options.Exclude,
+		Mappings:  d.options.Mappings,
+		Variables: d.vars,
+		Version:   d.options.Version,
+		Files:     files,
+		format:    d.options.LockFormat,
+	}, nil
+}
+
+// forgetFiles removes the given paths, and the files below them, from the
+// lock: they were left as they were in dest and are not template content
+func (l *Lock) forgetFiles(paths []string) {
+	for _, p := range paths {
+		for name := range l.Files {
+			if name == p || strings.HasPrefix(name, p+"/") {
+				delete(l.Files, name)
+			}
+		}
+	}
+}
+
+// checksumFiles returns the SHA-256 of every regular file under dir, keyed
+// by slash-separated relative path
+func checksumFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == LockFileName {
+			return nil
+		}
+
+		sum, err := checksumFile(path)
+		if err != nil {
+			return err
+		}
+		files[rel] = sum
+		return nil
+	})
+
+	return files, err
+}
+
+// checksumFile returns the hex SHA-256 of a file
+func checksumFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadLock reads the lock file in dir (JSON or YAML)
+func ReadLock(dir string) (*Lock, error) {
+	data, err := os.ReadFile(filepath.Join(dir, LockFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no %s found in %s", LockFileName, dir)
+		}
+		return nil, err
+	}
+
+	lock := Lock{format: LockYAML}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		lock.format = LockJSON
+	}
+
+	// YAML is a superset of JSON, so one decoder reads both formats
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", LockFileName, err)
+	}
+
+	return &lock, nil
+}
+
+// WriteLock writes the lock file into dir, in the format it was read in or
+// created with (JSON by default)
+func WriteLock(dir string, lock *Lock) error {
+	var data []byte
+	var err error
+	if lock.format == LockYAML {
+		data, err = yaml.Marshal(lock)
+	} else {
+		data, err = json.MarshalIndent(lock, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, LockFileName), data, 0644)
+}
+
+// ParseSource parses the locked source at the given ref
+func (l *Lock) ParseSource(ref string) (*Source, error) {
+	src, err := ParseSource(l.Source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source in %s: %w", LockFileName, err)
+	}
+	if ref != "" {
+		src.Ref = ref
+	}
+	return src, nil
+}
@@ -0,0 +1,22 @@
+package degit
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestLockForgetFiles(t *testing.T) {
+	lock := &Lock{Files: map[string]string{
+		"a.txt":       "1",
+		"a.txt.orig":  "2",
+		"dir/b.txt":   "3",
+		"dir/c/d.txt": "4",
+		"dirx/e.txt":  "5",
+	}}
+	lock.forgetFiles([]string{"a.txt", "dir"})
+
+	want := map[string]string{"a.txt.orig": "2", "dirx/e.txt": "5"}
+	if !maps.Equal(lock.Files, want) {
+		t.Errorf("Files = %v, want %v", lock.Files, want)
+	}
+}
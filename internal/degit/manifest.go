@@ -0,0 +1,188 @@
+package degit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	sdk "github.com/ssgohq/ss-plugin-sdk"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestNames are the file names of a template manifest, in order of
+// precedence
+var ManifestNames = []string{"degit.json", "degit.jsonc", "degit.yaml", "degit.yml"}
+
+// ManifestVersion is the latest version of the manifest object form
+const ManifestVersion = 2
+
+// Manifest is the contents of degit.json: either a list of actions or an
+// object declaring variables and actions
+type Manifest struct {
+	Schema    string     `json:"$schema,omitempty"`   // JSON Schema URL, for editors (see SchemaURL)
+	Version   int        `json:"version,omitempty"`   // Format version of the object form
+	Variables []Variable `json:"variables,omitempty"` // Template variables
+	Actions   []Action   `json:"actions,omitempty"`   // Actions run after extraction
+}
+
+// isManifestName reports whether name is the file name of a manifest
+func isManifestName(name string) bool {
+	return slices.Contains(ManifestNames, name)
+}
+
+// LoadManifest loads the manifest (degit.json, degit.jsonc or degit.yaml)
+// from the destination directory and removes it. A missing file yields an
+// empty manifest; an invalid one fails (see checkManifest).
+func LoadManifest(destDir string) (*Manifest, error) {
+	var found []string
+	for _, name := range ManifestNames {
+		if _, err := os.Stat(filepath.Join(destDir, name)); err == nil {
+			found = append(found, name)
+		}
+	}
+	if len(found) == 0 {
+		return &Manifest{}, nil // No manifest
+	}
+
+	data, err := os.ReadFile(filepath.Join(destDir, found[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := checkManifest(found[0], data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the manifest after loading, along with any ignored ones
+	for i, name := range found {
+		if i > 0 {
+			sdk.Warning(fmt.Sprintf("Ignoring %s (using %s)", name, found[0]))
+		}
+		_ = os.Remove(filepath.Join(destDir, name))
+	}
+
+	return manifest, nil
+}
+
+// checkManifest validates and parses the contents of a manifest before its
+// actions run. Unknown fields are reported as warnings; any other problem
+// fails with every problem found.
+func checkManifest(name string, data []byte) (*Manifest, error) {
+	var problems []string
+	for _, problem := range ValidateManifest(name, data) {
+		if problem.Warning {
+			sdk.Warning(problem.Error())
+			continue
+		}
+		problems = append(problems, problem.Error())
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid manifest:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	return ParseManifest(name, data)
+}
+
+// ParseManifest parses the contents of the manifest file with the given
+// name. JSON manifests may contain comments and trailing commas.
+func ParseManifest(name string, data []byte) (*Manifest, error) {
+	switch path.Ext(name) {
+	case ".yaml", ".yml":
+		var value interface{}
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		// Actions are decoded from JSON (see Action.UnmarshalJSON)
+		converted, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		data = converted
+	default:
+		data = stripJSONComments(data)
+	}
+
+	var manifest Manifest
+
+	// A top-level array is a plain list of actions
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &manifest.Actions); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		return &manifest, nil
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 || bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return &manifest, nil
+	}
+
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if manifest.Version < 0 || manifest.Version > ManifestVersion {
+		return nil, fmt.Errorf("%s version %d is not supported (latest is %d)", name, manifest.Version, ManifestVersion)
+	}
+	return &manifest, nil
+}
+
+// stripJSONComments blanks out // and /* */ comments and trailing commas
+// in JSON, keeping line and column positions of everything else
+func stripJSONComments(data []byte) []byte {
+	out := bytes.Clone(data)
+	inString := false
+	lastComma := -1 // Position of a comma possibly followed by ] or }
+
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+
+		case c == '"':
+			inString = true
+			lastComma = -1
+
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+
+		case c == ',':
+			lastComma = i
+
+		case c == ']' || c == '}':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			lastComma = -1
+		}
+	}
+
+	return out
+}
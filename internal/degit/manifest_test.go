@@ -0,0 +1,131 @@
+package degit
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStripJSONComments(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "line comment",
+			in:   "{\"a\": 1} // note\n",
+			want: "{\"a\": 1}        \n",
+		},
+		{
+			name: "slashes inside strings",
+			in:   `{"url": "https://example.com//x", "b": "/* no */"}`,
+			want: `{"url": "https://example.com//x", "b": "/* no */"}`,
+		},
+		{
+			name: "escaped quote inside string",
+			in:   `{"a": "say \"hi\" // still a string"}`,
+			want: `{"a": "say \"hi\" // still a string"}`,
+		},
+		{
+			name: "block comment",
+			in:   `{/* x */"a": 1}`,
+			want: `{       "a": 1}`,
+		},
+		{
+			name: "multi-line block comment keeps newlines",
+			in:   "[\n/* one\ntwo */ 1]",
+			want: "[\n      \n       1]",
+		},
+		{
+			name: "unterminated block comment",
+			in:   "[1] /* open",
+			want: "[1]        ",
+		},
+		{
+			name: "trailing comma in array",
+			in:   "[1, 2, ]",
+			want: "[1, 2  ]",
+		},
+		{
+			name: "trailing comma in object before comment",
+			in:   "{\"a\": 1, // last\n}",
+			want: "{\"a\": 1         \n}",
+		},
+		{
+			name: "comma inside string is kept",
+			in:   `["a,", "]"]`,
+			want: `["a,", "]"]`,
+		},
+		{
+			name: "separating commas are kept",
+			in:   `[1, {"a": [2, 3]}, 4]`,
+			want: `[1, {"a": [2, 3]}, 4]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(stripJSONComments([]byte(tt.in)))
+			if got != tt.want {
+				t.Errorf("stripJSONComments(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if len(got) != len(tt.in) {
+				t.Errorf("stripJSONComments(%q) changed the length from %d to %d", tt.in, len(tt.in), len(got))
+			}
+		})
+	}
+}
+
+func TestStripJSONCommentsParses(t *testing.T) {
+	in := `{
+  // Actions run after extraction
+  "actions": [
+    { "action": "remove", "files": ["LICENSE", "docs/**"], }, /* trailing */
+    { "action": "run", "command": "echo // not a comment", },
+  ],
+}`
+	var manifest Manifest
+	if err := json.Unmarshal(stripJSONComments([]byte(in)), &manifest); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(manifest.Actions) != 2 || manifest.Actions[1].Command != "echo // not a comment" {
+		t.Errorf("actions = %+v", manifest.Actions)
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		actions int
+		version int
+		err     bool
+	}{
+		{name: "list of actions", file: "degit.json", data: `[{"action": "remove", "files": "a"}]`, actions: 1},
+		{name: "object form", file: "degit.json", data: `{"version": 2, "actions": [{"action": "run", "command": "x"}]}`, actions: 1, version: 2},
+		{name: "jsonc", file: "degit.jsonc", data: "[\n  // remove\n  {\"action\": \"remove\", \"files\": \"a\"},\n]", actions: 1},
+		{name: "yaml", file: "degit.yaml", data: "version: 2\nactions:\n  - action: remove\n    files: [a, b]\n", actions: 1, version: 2},
+		{name: "empty", file: "degit.json", data: "  \n"},
+		{name: "unsupported version", file: "degit.json", data: `{"version": 3}`, err: true},
+		{name: "syntax error", file: "degit.json", data: `[{"action": }]`, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, err := ParseManifest(tt.file, []byte(tt.data))
+			if tt.err {
+				if err == nil {
+					t.Errorf("ParseManifest() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseManifest() error = %v", err)
+			}
+			if len(manifest.Actions) != tt.actions || manifest.Version != tt.version {
+				t.Errorf("ParseManifest() = %d actions, version %d; want %d, %d", len(manifest.Actions), manifest.Version, tt.actions, tt.version)
+			}
+		})
+	}
+}
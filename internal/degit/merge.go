@@ -0,0 +1,132 @@
+package degit
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff/lcs"
+)
+
+// Conflict markers written by merge3
+const (
+	markerLocal  = "<<<<<<<"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>>"
+)
+
+// isBinary reports whether data looks like binary content (a NUL byte in
+// the first 8000 bytes, like git)
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// splitLines splits text into lines, keeping line endings
+func splitLines(text []byte) []string {
+	lines := strings.SplitAfter(string(text), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineMatches maps each line of a to the index of the matching line of b in
+// their longest common subsequence, or -1 if it has no match
+func lineMatches(a, b []string) []int {
+	matches := make([]int, len(a))
+	ai, bi := 0, 0
+	for _, d := range lcs.DiffLines(a, b) {
+		for ; ai < d.Start; ai, bi = ai+1, bi+1 {
+			matches[ai] = bi
+		}
+		for ; ai < d.End; ai++ {
+			matches[ai] = -1
+		}
+		bi = d.ReplEnd
+	}
+	for ; ai < len(a); ai, bi = ai+1, bi+1 {
+		matches[ai] = bi
+	}
+	return matches
+}
+
+// merge3 performs a line-based three-way merge of the local and theirs
+// versions of a file against their common base. Changes made on only one
+// side are applied; overlapping changes are written between conflict
+// markers labelled localLabel and theirsLabel. It reports whether the
+// result contains conflicts.
+func merge3(base, local, theirs []byte, localLabel, theirsLabel string) ([]byte, bool) {
+	o, a, b := splitLines(base), splitLines(local), splitLines(theirs)
+	ma, mb := lineMatches(o, a), lineMatches(o, b)
+
+	var out strings.Builder
+	conflict := false
+
+	// unstable emits a region where at least one side differs from base
+	unstable := func(oc, ac, bc []string) {
+		switch {
+		case slices.Equal(ac, oc):
+			writeLines(&out, bc)
+		case slices.Equal(bc, oc), slices.Equal(ac, bc):
+			writeLines(&out, ac)
+		default:
+			conflict = true
+			out.WriteString(markerLocal + " " + localLabel + "\n")
+			writeLines(&out, ac)
+			terminateLine(&out)
+			out.WriteString(markerSep + "\n")
+			writeLines(&out, bc)
+			terminateLine(&out)
+			out.WriteString(markerTheirs + " " + theirsLabel + "\n")
+		}
+	}
+
+	oi, ai, bi := 0, 0, 0
+	for {
+		// Copy lines that are unchanged on both sides
+		n := 0
+		for oi+n < len(o) && ma[oi+n] == ai+n && mb[oi+n] == bi+n {
+			n++
+		}
+		if n > 0 {
+			writeLines(&out, o[oi:oi+n])
+			oi, ai, bi = oi+n, ai+n, bi+n
+			continue
+		}
+
+		// Find the next base line kept by both sides
+		j := oi
+		for j < len(o) && (ma[j] < 0 || mb[j] < 0) {
+			j++
+		}
+		if j == len(o) {
+			if oi < len(o) || ai < len(a) || bi < len(b) {
+				unstable(o[oi:], a[ai:], b[bi:])
+			}
+			break
+		}
+
+		unstable(o[oi:j], a[ai:ma[j]], b[bi:mb[j]])
+		oi, ai, bi = j, ma[j], mb[j]
+	}
+
+	return []byte(out.String()), conflict
+}
+
+// writeLines appends lines to out
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+// terminateLine adds a newline if out does not end with one, so conflict
+// markers start on their own line
+func terminateLine(out *strings.Builder) {
+	if out.Len() > 0 && !strings.HasSuffix(out.String(), "\n") {
+		out.WriteString("\n")
+	}
+}
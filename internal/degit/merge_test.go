@@ -0,0 +1,145 @@
+package degit
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		local    string
+		theirs   string
+		want     string
+		conflict bool
+	}{
+		{
+			name:   "unchanged",
+			base:   "a\nb\nc\n",
+			local:  "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "local change only",
+			base:   "a\nb\nc\n",
+			local:  "a\nB\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "theirs change only",
+			base:   "a\nb\nc\n",
+			local:  "a\nb\nc\n",
+			theirs: "a\nb\nC\n",
+			want:   "a\nb\nC\n",
+		},
+		{
+			name:   "changes to different lines",
+			base:   "a\nb\nc\nd\ne\n",
+			local:  "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "a\nb\nc\n",
+			local:  "a\nX\nc\n",
+			theirs: "a\nX\nc\n",
+			want:   "a\nX\nc\n",
+		},
+		{
+			name:   "insertion at the start and the end",
+			base:   "a\nb\n",
+			local:  "first\na\nb\n",
+			theirs: "a\nb\nlast\n",
+			want:   "first\na\nb\nlast\n",
+		},
+		{
+			name:     "insertions at the start on both sides",
+			base:     "a\n",
+			local:    "local\na\n",
+			theirs:   "theirs\na\n",
+			want:     "<<<<<<< local\nlocal\n=======\ntheirs\n>>>>>>> template\na\n",
+			conflict: true,
+		},
+		{
+			name:     "insertions at the end on both sides",
+			base:     "a\n",
+			local:    "a\nlocal\n",
+			theirs:   "a\ntheirs\n",
+			want:     "a\n<<<<<<< local\nlocal\n=======\ntheirs\n>>>>>>> template\n",
+			conflict: true,
+		},
+		{
+			name:     "conflicting changes",
+			base:     "a\nb\nc\n",
+			local:    "a\nlocal\nc\n",
+			theirs:   "a\ntheirs\nc\n",
+			want:     "a\n<<<<<<< local\nlocal\n=======\ntheirs\n>>>>>>> template\nc\n",
+			conflict: true,
+		},
+		{
+			name:   "deleted locally, unchanged in theirs",
+			base:   "a\nb\nc\n",
+			local:  "a\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nc\n",
+		},
+		{
+			name:     "deleted locally, changed in theirs",
+			base:     "a\nb\nc\n",
+			local:    "a\nc\n",
+			theirs:   "a\nB\nc\n",
+			want:     "a\n<<<<<<< local\n=======\nB\n>>>>>>> template\nc\n",
+			conflict: true,
+		},
+		{
+			name:     "conflict without final newline",
+			base:     "a\nb",
+			local:    "a\nlocal",
+			theirs:   "a\ntheirs",
+			want:     "a\n<<<<<<< local\nlocal\n=======\ntheirs\n>>>>>>> template\n",
+			conflict: true,
+		},
+		{
+			name:   "empty base",
+			base:   "",
+			local:  "",
+			theirs: "new\n",
+			want:   "new\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := merge3([]byte(tt.base), []byte(tt.local), []byte(tt.theirs), "local", "template")
+			if string(got) != tt.want {
+				t.Errorf("merge3() = %q, want %q", got, tt.want)
+			}
+			if conflict != tt.conflict {
+				t.Errorf("merge3() conflict = %v, want %v", conflict, tt.conflict)
+			}
+		})
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a\n"}},
+		{"a\r\nb", []string{"a\r\n", "b"}},
+		{"\n\n", []string{"\n", "\n"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitLines([]byte(tt.text)); !slices.Equal(got, tt.want) {
+			t.Errorf("splitLines(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package degit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	sdk "github.com/ssgohq/ss-plugin-sdk"
+)
+
+// devNull is the path unified diffs use for created and deleted files
+const devNull = "/dev/null"
+
+// hunkHeader matches the "@@ -start,count +start,count @@" line of a hunk
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// filePatch is the part of a unified diff changing one file
+type filePatch struct {
+	oldPath string
+	newPath string
+	hunks   []hunk
+}
+
+// hunk is a change to a run of lines. Lines keep their line endings.
+type hunk struct {
+	oldStart int
+	old      []string // Context and removed lines
+	new      []string // Context and added lines
+}
+
+// path returns the slash-separated path of the file the patch changes
+func (fp *filePatch) path() string {
+	if fp.newPath == devNull {
+		return fp.oldPath
+	}
+	return fp.newPath
+}
+
+// parsePatch parses a unified diff (as written by diff -u or git diff)
+// into the changes to each file
+func parsePatch(text string) ([]*filePatch, error) {
+	var patches []*filePatch
+	var current *filePatch
+	lines := splitLines([]byte(text))
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			current = &filePatch{
+				oldPath: patchPath(line[4:], "a/"),
+				newPath: patchPath(strings.TrimRight(lines[i+1][4:], "\r\n"), "b/"),
+			}
+			patches = append(patches, current)
+			i++
+
+		case strings.HasPrefix(line, "@@"):
+			if current == nil {
+				return nil, fmt.Errorf("hunk without file header: %q", line)
+			}
+			h, next, err := parseHunk(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			current.hunks = append(current.hunks, h)
+			i = next - 1
+		}
+		// Anything else ("diff --git", "index", ...) is ignored
+	}
+
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no file changes found")
+	}
+	return patches, nil
+}
+
+// patchPath returns the path of a "---" or "+++" header without its
+// timestamp and "a/" or "b/" prefix
+func patchPath(header string, prefix string) string {
+	if i := strings.IndexByte(header, '\t'); i >= 0 {
+		header = header[:i]
+	}
+	header = strings.TrimSpace(header)
+	if header == devNull {
+		return header
+	}
+	return strings.TrimPrefix(header, prefix)
+}
+
+// parseHunk parses the hunk starting at lines[start], returning it and the
+// index of the line after it
+func parseHunk(lines []string, start int) (hunk, int, error) {
+	header := strings.TrimRight(lines[start], "\r\n")
+	m := hunkHeader.FindStringSubmatch(header)
+	if m == nil {
+		return hunk{}, 0, fmt.Errorf("invalid hunk header: %q", header)
+	}
+
+	h := hunk{}
+	h.oldStart, _ = strconv.Atoi(m[1])
+	oldCount, newCount := 1, 1
+	if m[2] != "" {
+		oldCount, _ = strconv.Atoi(m[2])
+	}
+	if m[4] != "" {
+		newCount, _ = strconv.Atoi(m[4])
+	}
+
+	i := start + 1
+	var last byte
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" {
+			continue
+		}
+		if line[0] == '\\' {
+			// "\ No newline at end of file" applies to the previous line
+			if last == ' ' || last == '-' {
+				h.old[len(h.old)-1] = strings.TrimRight(h.old[len(h.old)-1], "\r\n")
+			}
+			if last == ' ' || last == '+' {
+				h.new[len(h.new)-1] = strings.TrimRight(h.new[len(h.new)-1], "\r\n")
+			}
+			continue
+		}
+		if len(h.old) == oldCount && len(h.new) == newCount {
+			break
+		}
+
+		// Some tools strip the trailing space of empty context lines
+		if line == "\n" || line == "\r\n" {
+			line = " " + line
+		}
+		last = line[0]
+		switch last {
+		case ' ':
+			h.old = append(h.old, line[1:])
+			h.new = append(h.new, line[1:])
+		case '-':
+			h.old = append(h.old, line[1:])
+		case '+':
+			h.new = append(h.new, line[1:])
+		default:
+			return hunk{}, 0, fmt.Errorf("unexpected line in hunk %q: %q", header, strings.TrimRight(line, "\r\n"))
+		}
+	}
+
+	if len(h.old) != oldCount || len(h.new) != newCount {
+		return hunk{}, 0, fmt.Errorf("truncated hunk %q", header)
+	}
+	return h, i, nil
+}
+
+// applyHunks applies hunks to content. A hunk whose lines moved is applied
+// at the nearest position where its context and removed lines match.
+func applyHunks(content []byte, hunks []hunk) ([]byte, error) {
+	lines := splitLines(content)
+	var out []string
+	pos := 0   // Next line of content to copy
+	shift := 0 // Offset of the previous hunk from its stated position
+
+	for n, h := range hunks {
+		at := findHunk(lines, h, max(h.oldStart-1, 0)+shift, pos)
+		if at < 0 {
+			return nil, fmt.Errorf("hunk %d does not apply", n+1)
+		}
+		shift = at - max(h.oldStart-1, 0)
+
+		out = append(out, lines[pos:at]...)
+		out = append(out, h.new...)
+		pos = at + len(h.old)
+	}
+	out = append(out, lines[pos:]...)
+
+	return []byte(strings.Join(out, "")), nil
+}
+
+// findHunk returns the line index closest to want, and not before from,
+// where the old lines of h match, or -1
+func findHunk(lines []string, h hunk, want int, from int) int {
+	for delta := 0; ; delta++ {
+		before, after := want-delta, want+delta
+		if before < from && after+len(h.old) > len(lines) {
+			return -1
+		}
+		if after >= from && after+len(h.old) <= len(lines) && slices.Equal(lines[after:after+len(h.old)], h.old) {
+			return after
+		}
+		if delta > 0 && before >= from && before+len(h.old) <= len(lines) && slices.Equal(lines[before:before+len(h.old)], h.old) {
+			return before
+		}
+	}
+}
+
+// executePatchAction executes a patch action (applies a unified diff
+// shipped in the template to the extracted files)
+func executePatchAction(action Action, destDir string) error {
+	if action.Patch == "" {
+		return fmt.Errorf("patch action requires 'patch' field")
+	}
+
+	patchFile := filepath.Join(destDir, action.Patch)
+	if !hasPrefix(patchFile, destDir) {
+		sdk.Warning(fmt.Sprintf("Skipping path traversal attempt: %s", action.Patch))
+		return nil
+	}
+
+	data, err := os.ReadFile(patchFile)
+	if err != nil {
+		return err
+	}
+
+	patches, err := parsePatch(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid patch %s: %w", action.Patch, err)
+	}
+
+	for _, fp := range patches {
+		rel := fp.path()
+		filePath := filepath.Join(destDir, filepath.FromSlash(rel))
+		if !hasPrefix(filePath, destDir) || filePath == filepath.Clean(destDir) {
+			sdk.Warning(fmt.Sprintf("Skipping path traversal attempt: %s", rel))
+			continue
+		}
+
+		if err := applyFilePatch(fp, filePath); err != nil {
+			return fmt.Errorf("failed to patch %s: %w", rel, err)
+		}
+		sdk.Info(fmt.Sprintf("Patched: %s", rel))
+	}
+
+	return nil
+}
+
+// applyFilePatch applies the changes of one file, creating or deleting it
+// as the patch says
+func applyFilePatch(fp *filePatch, filePath string) error {
+	var content []byte
+	mode := os.FileMode(0644)
+	if fp.oldPath != devNull {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		mode = info.Mode().Perm()
+		if content, err = os.ReadFile(filePath); err != nil {
+			return err
+		}
+	} else if _, err := os.Lstat(filePath); err == nil {
+		return fmt.Errorf("file already exists")
+	}
+
+	patched, err := applyHunks(content, fp.hunks)
+	if err != nil {
+		return err
+	}
+
+	if fp.newPath == devNull {
+		if len(patched) > 0 {
+			return fmt.Errorf("file to delete does not match the patch")
+		}
+		return os.Remove(filePath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, patched, mode)
+}
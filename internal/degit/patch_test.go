@@ -0,0 +1,261 @@
+package degit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePatch(t *testing.T) {
+	patch := `diff --git a/main.go b/main.go
+index 1234567..89abcde 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-var x = 1
++var x = 2
+ // end
+@@ -10 +10,2 @@ func f() {
+ }
++// added
+--- /dev/null	2024-01-01 00:00:00.000000000 +0000
++++ b/new.txt	2024-01-01 00:00:00.000000000 +0000
+@@ -0,0 +1 @@
++hello
+\ No newline at end of file
+`
+	patches, err := parsePatch(patch)
+	if err != nil {
+		t.Fatalf("parsePatch() error = %v", err)
+	}
+	if len(patches) != 2 {
+		t.Fatalf("parsePatch() = %d file patches, want 2", len(patches))
+	}
+
+	modified := patches[0]
+	if modified.oldPath != "main.go" || modified.newPath != "main.go" || len(modified.hunks) != 2 {
+		t.Errorf("first patch = %s -> %s with %d hunks", modified.oldPath, modified.newPath, len(modified.hunks))
+	}
+	h := modified.hunks[0]
+	if h.oldStart != 1 || strings.Join(h.old, "") != "package main\nvar x = 1\n// end\n" || strings.Join(h.new, "") != "package main\nvar x = 2\n// end\n" {
+		t.Errorf("first hunk = %+v", h)
+	}
+	if h := modified.hunks[1]; h.oldStart != 10 || len(h.old) != 1 || len(h.new) != 2 {
+		t.Errorf("second hunk = %+v, want a one-line count default", h)
+	}
+
+	created := patches[1]
+	if created.oldPath != devNull || created.newPath != "new.txt" || created.path() != "new.txt" {
+		t.Errorf("second patch = %s -> %s", created.oldPath, created.newPath)
+	}
+	if got := strings.Join(created.hunks[0].new, ""); got != "hello" {
+		t.Errorf("created contents = %q, want %q without newline", got, "hello")
+	}
+}
+
+func TestParsePatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"empty", "", "no file changes found"},
+		{"hunk without header", "@@ -1 +1 @@\n-a\n+b\n", `hunk without file header: "@@ -1 +1 @@"`},
+		{"invalid hunk header", "--- a/x\n+++ b/x\n@@ -a +b @@\n", `invalid hunk header: "@@ -a +b @@"`},
+		{"truncated hunk", "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n", `truncated hunk "@@ -1,2 +1,2 @@"`},
+		{"unexpected line", "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n?b\n", `unexpected line in hunk "@@ -1,2 +1,2 @@": "?b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePatch(tt.patch)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("parsePatch() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyHunks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		patch   string
+		want    string
+		err     string
+	}{
+		{
+			name:    "in place",
+			content: "a\nb\nc\n",
+			patch:   "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			want:    "a\nB\nc\n",
+		},
+		{
+			name:    "moved down",
+			content: "x\ny\na\nb\nc\n",
+			patch:   "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			want:    "x\ny\na\nB\nc\n",
+		},
+		{
+			name:    "moved up",
+			content: "a\nb\nc\nd\n",
+			patch:   "@@ -5,2 +5,2 @@\n c\n-d\n+D\n",
+			want:    "a\nb\nc\nD\n",
+		},
+		{
+			name:    "offset carried to later hunks",
+			content: "new\na\nb\nc\nd\ne\nf\ng\n",
+			patch:   "@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -6,2 +6,2 @@\n f\n-g\n+G\n",
+			want:    "new\nA\nb\nc\nd\ne\nf\nG\n",
+		},
+		{
+			name:    "nearest match",
+			content: "a\nx\na\na\n",
+			patch:   "@@ -3 +3 @@\n-a\n+A\n",
+			want:    "a\nx\nA\na\n",
+		},
+		{
+			name:    "add final newline",
+			content: "a\nb",
+			patch:   "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+			want:    "a\nb\n",
+		},
+		{
+			name:    "remove final newline",
+			content: "a\nb\n",
+			patch:   "@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+			want:    "a\nb",
+		},
+		{
+			name:    "context without final newline",
+			content: "a\nb",
+			patch:   "@@ -1,2 +1,3 @@\n+first\n a\n b\n\\ No newline at end of file\n",
+			want:    "first\na\nb",
+		},
+		{
+			name:    "empty context line without space",
+			content: "a\n\nb\n",
+			patch:   "@@ -1,3 +1,3 @@\n a\n\n-b\n+B\n",
+			want:    "a\n\nB\n",
+		},
+		{
+			name:    "context mismatch",
+			content: "a\nb\nc\n",
+			patch:   "@@ -1,3 +1,3 @@\n a\n-x\n+X\n c\n",
+			err:     "hunk 1 does not apply",
+		},
+		{
+			name:    "overlapping hunks",
+			content: "a\nb\n",
+			patch:   "@@ -1 +1 @@\n-a\n+A\n@@ -1 +1 @@\n-a\n+A\n",
+			err:     "hunk 2 does not apply",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patches, err := parsePatch("--- a/f\n+++ b/f\n" + tt.patch)
+			if err != nil {
+				t.Fatalf("parsePatch() error = %v", err)
+			}
+			got, err := applyHunks([]byte(tt.content), patches[0].hunks)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("applyHunks() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyHunks() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("applyHunks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyFilePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing *string // Contents before the patch, nil if missing
+		patch    string
+		want     *string // Contents after the patch, nil if deleted
+		err      string
+	}{
+		{
+			name:  "create",
+			patch: "--- /dev/null\n+++ b/f.txt\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+			want:  ptr("a\nb\n"),
+		},
+		{
+			name:     "create existing",
+			existing: ptr("x\n"),
+			patch:    "--- /dev/null\n+++ b/f.txt\n@@ -0,0 +1 @@\n+a\n",
+			err:      "file already exists",
+		},
+		{
+			name:     "delete",
+			existing: ptr("a\nb\n"),
+			patch:    "--- a/f.txt\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:     "delete changed file",
+			existing: ptr("a\nb\nc\n"),
+			patch:    "--- a/f.txt\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+			err:      "file to delete does not match the patch",
+		},
+		{
+			name:     "modify",
+			existing: ptr("a\nb\n"),
+			patch:    "--- a/f.txt\n+++ b/f.txt\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+			want:     ptr("a\nc\n"),
+		},
+		{
+			name:  "modify missing file",
+			patch: "--- a/f.txt\n+++ b/f.txt\n@@ -1 +1 @@\n-a\n+b\n",
+			err:   "no such file or directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "f.txt")
+			if tt.existing != nil {
+				if err := os.WriteFile(file, []byte(*tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			patches, err := parsePatch(tt.patch)
+			if err != nil {
+				t.Fatalf("parsePatch() error = %v", err)
+			}
+			err = applyFilePatch(patches[0], file)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("applyFilePatch() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyFilePatch() error = %v", err)
+			}
+
+			data, err := os.ReadFile(file)
+			switch {
+			case tt.want == nil && !os.IsNotExist(err):
+				t.Errorf("file not deleted (read error = %v)", err)
+			case tt.want != nil && string(data) != *tt.want:
+				t.Errorf("file = %q (read error = %v), want %q", data, err, *tt.want)
+			}
+		})
+	}
+}
+
+// ptr returns a pointer to s
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,153 @@
+package degit
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	sdk "github.com/ssgohq/ss-plugin-sdk"
+)
+
+// Plan actions for files
+const (
+	PlanCreate    = "create"    // File does not exist in the destination
+	PlanOverwrite = "overwrite" // File exists and would be replaced
+	PlanSkip      = "skip"      // File is excluded by filters or kept by the conflict policy
+	PlanBackup    = "backup"    // File exists and would be renamed to *.orig
+	PlanConflict  = "conflict"  // File exists and would fail the clone or be prompted for
+)
+
+// PlanEntry describes what would happen to a single file
+type PlanEntry struct {
+	Path   string // Path relative to the destination
+	Action string // One of the Plan* constants
+}
+
+// Plan describes what a clone would do without touching the destination
+type Plan struct {
+	Source    *Source
+	Hash      string      // Resolved commit hash
+	Dest      string      // Destination directory
+	Entries   []PlanEntry // Files in archive order
+	Variables []Variable  // Variables declared in degit.json
+	Actions   []Action    // degit.json actions that would run
+
+	manifests map[string][]byte // Contents of the manifest files in the archive, by name
+}
+
+// Count returns the number of entries with the given action
+func (p *Plan) Count(action string) int {
+	n := 0
+	for _, e := range p.Entries {
+		if e.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Plan resolves the ref and reads the archive (from cache or download) to
+// report which files a clone would create, overwrite or skip and which
+// degit.json actions it would run. The destination is not modified.
+func (d *Degit) Plan(src *Source, dest string) (*Plan, error) {
+	// Check if destination is empty, unless a conflict policy is set
+	if d.options.Conflict == "" {
+		if err := d.checkDestEmpty(dest); err != nil {
+			return nil, err
+		}
+	}
+
+	archive, err := d.fetch(src, GetRepoCacheDir(src))
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	plan := &Plan{
+		Source: src,
+		Hash:   archive.Hash,
+		Dest:   dest,
+	}
+
+	ex, err := newExtractor(dest, archive.Options)
+	if err != nil {
+		return nil, err
+	}
+	ex.plan = plan
+	ex.conflict = d.options.Conflict
+
+	if err := ex.walkArchive(archive.Path); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	for _, name := range ManifestNames {
+		data, ok := plan.manifests[name]
+		if !ok {
+			continue
+		}
+		manifest, err := checkManifest(name, data)
+		if err != nil {
+			sdk.Warning(fmt.Sprintf("Failed to load %s: %v", name, err))
+		} else {
+			plan.Variables = manifest.Variables
+			plan.Actions = manifest.Actions
+		}
+		break
+	}
+
+	return plan, nil
+}
+
+// planEntry records an archive entry in the extractor's plan instead of
+// writing it
+func (ex *extractor) planEntry(entry archiveEntry, destPath string, reader io.Reader, action string) error {
+	if entry.Type == tar.TypeDir {
+		return nil
+	}
+
+	rel, err := filepath.Rel(ex.destDir, destPath)
+	if err != nil {
+		return err
+	}
+	rel = filepath.ToSlash(rel)
+
+	// The manifest is consumed by the clone, so it is reported as actions
+	if isManifestName(rel) && action != PlanSkip {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		if ex.plan.manifests == nil {
+			ex.plan.manifests = make(map[string][]byte)
+		}
+		ex.plan.manifests[rel] = data
+		return nil
+	}
+
+	if action == "" {
+		action = PlanCreate
+		if _, err := os.Lstat(destPath); err == nil {
+			action = conflictPlanAction(ex.conflict)
+		}
+	}
+
+	ex.plan.Entries = append(ex.plan.Entries, PlanEntry{Path: rel, Action: action})
+	return nil
+}
+
+// conflictPlanAction returns the plan action for an existing file under a
+// conflict policy
+func conflictPlanAction(policy string) string {
+	switch policy {
+	case ConflictSkip:
+		return PlanSkip
+	case ConflictBackup:
+		return PlanBackup
+	case ConflictError, ConflictPrompt:
+		return PlanConflict
+	default:
+		return PlanOverwrite
+	}
+}
@@ -0,0 +1,227 @@
+package degit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+)
+
+// Prompt types for prompt actions
+const (
+	PromptText        = "text"
+	PromptSelect      = "select"
+	PromptConfirm     = "confirm"
+	PromptMultiSelect = "multiselect"
+)
+
+// interactive reports whether prompts can be shown to the user
+func (d *Degit) interactive() bool {
+	return !d.options.NonInteractive && isTerminal(os.Stdin)
+}
+
+// LoadAnswers reads prompt answers from a JSON object file. Booleans,
+// numbers and lists (joined with commas) are converted to strings.
+func LoadAnswers(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse answers file: %w", err)
+	}
+
+	answers := make(map[string]string, len(raw))
+	for name, value := range raw {
+		answers[name] = formatAnswer(value)
+	}
+	return answers, nil
+}
+
+// formatAnswer converts a JSON value to a variable value
+func formatAnswer(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatAnswer(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// executePromptAction asks the question of a prompt action and stores the
+// answer as a variable, substituting it in the files extracted so far.
+// Variables that already have a value (from --var or an answers file) are
+// not asked again.
+func executePromptAction(action Action, destDir string, degitInst *Degit) error {
+	if action.Name == "" {
+		return fmt.Errorf("prompt action requires 'name' field")
+	}
+	if _, ok := degitInst.vars[action.Name]; ok {
+		return nil
+	}
+
+	value, err := askPrompt(action, degitInst.interactive())
+	if err != nil {
+		return err
+	}
+
+	degitInst.vars[action.Name] = value
+	return renderTemplate(destDir, map[string]string{action.Name: value})
+}
+
+// askPrompt asks the question of a prompt action. In non-interactive mode
+// the default answer is used.
+func askPrompt(action Action, interactive bool) (string, error) {
+	switch action.Type {
+	case "", PromptText, PromptConfirm:
+	case PromptSelect, PromptMultiSelect:
+		if len(action.Choices) == 0 {
+			return "", fmt.Errorf("%s prompt %s requires 'choices'", action.Type, action.Name)
+		}
+	default:
+		return "", fmt.Errorf("unknown prompt type: %s", action.Type)
+	}
+
+	defaultValue := formatAnswer(action.Default)
+	if !interactive {
+		if action.Default == nil {
+			return "", fmt.Errorf("no answer for %s (use --var %s=... or --answers)", action.Name, action.Name)
+		}
+		return defaultValue, nil
+	}
+
+	message := action.Message
+	if message == "" {
+		message = action.Name
+	}
+
+	var value string
+	var err error
+	switch action.Type {
+	case PromptSelect:
+		value, err = promptSelect(message, action.Choices, defaultValue)
+	case PromptConfirm:
+		value, err = promptConfirm(message, defaultValue == "true")
+	case PromptMultiSelect:
+		value, err = promptMultiSelect(message, action.Choices, strings.Split(defaultValue, ","))
+	default:
+		value, err = promptText(message, defaultValue)
+	}
+
+	if err != nil {
+		if err == promptui.ErrInterrupt || err == promptui.ErrEOF {
+			return "", ErrUserCancelled
+		}
+		return "", fmt.Errorf("prompt failed: %w", err)
+	}
+	return value, nil
+}
+
+// promptText asks for a line of text
+func promptText(message string, defaultValue string) (string, error) {
+	prompt := promptui.Prompt{
+		Label:   message,
+		Default: defaultValue,
+	}
+	return prompt.Run()
+}
+
+// promptSelect asks to pick one of choices
+func promptSelect(message string, choices []string, defaultValue string) (string, error) {
+	prompt := promptui.Select{
+		Label:     message,
+		Items:     choices,
+		Size:      10,
+		CursorPos: max(slices.Index(choices, defaultValue), 0),
+	}
+	_, value, err := prompt.Run()
+	return value, err
+}
+
+// promptConfirm asks a yes/no question, returning "true" or "false"
+func promptConfirm(message string, defaultYes bool) (string, error) {
+	prompt := promptui.Prompt{
+		Label:     message,
+		IsConfirm: true,
+	}
+	if defaultYes {
+		prompt.Default = "y"
+	}
+
+	_, err := prompt.Run()
+	switch {
+	case err == nil:
+		return "true", nil
+	case err == promptui.ErrAbort:
+		// promptui reports "no" as an abort
+		return "false", nil
+	default:
+		return "", err
+	}
+}
+
+// promptMultiSelect asks to pick any number of choices, returning them
+// joined with commas. Choices are toggled one at a time until "Done" is
+// selected.
+func promptMultiSelect(message string, choices []string, defaults []string) (string, error) {
+	selected := make([]bool, len(choices))
+	for i, choice := range choices {
+		selected[i] = slices.Contains(defaults, choice)
+	}
+
+	const done = "Done"
+	cursor := 0
+	for {
+		items := make([]string, 0, len(choices)+1)
+		for i, choice := range choices {
+			mark := "[ ]"
+			if selected[i] {
+				mark = "[x]"
+			}
+			items = append(items, mark+" "+choice)
+		}
+		items = append(items, done)
+
+		prompt := promptui.Select{
+			Label:     message,
+			Items:     items,
+			Size:      10,
+			CursorPos: cursor,
+		}
+		idx, _, err := prompt.Run()
+		if err != nil {
+			return "", err
+		}
+		if idx == len(choices) {
+			break
+		}
+		selected[idx] = !selected[idx]
+		cursor = idx
+	}
+
+	var values []string
+	for i, choice := range choices {
+		if selected[i] {
+			values = append(values, choice)
+		}
+	}
+	return strings.Join(values, ","), nil
+}
@@ -0,0 +1,272 @@
+package degit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	sdk "github.com/ssgohq/ss-plugin-sdk"
+
+	"github.com/ssgohq/ss-plugin-degit/internal/auth"
+)
+
+// Provider abstracts a git hosting platform (GitHub, GitLab, ...)
+//
+// Each supported site is one Provider registered in the provider registry,
+// so adding a new host means implementing this interface and calling
+// RegisterProvider instead of editing every site switch.
+type Provider interface {
+	// Name returns the site key used in sources and cache paths (e.g. "github")
+	Name() string
+
+	// BaseURL returns the web URL of the host (e.g. "https://github.com")
+	BaseURL() string
+
+	// FetchRefs lists the branches, tags and HEAD of a repository
+	FetchRefs(src *Source) ([]Ref, error)
+
+	// ArchiveURL returns the URL of the tarball for a commit hash or ref
+	ArchiveURL(src *Source, hash string) string
+
+	// Authenticate adds credentials to a request sent to this host
+	Authenticate(req *http.Request)
+
+	// Download saves the tarball for a commit hash to destPath
+	Download(src *Source, hash string, destPath string, opts DownloadOptions) error
+
+	// CheckAccess reports whether the repository is accessible
+	CheckAccess(src *Source) (bool, error)
+}
+
+// providers holds the registered providers keyed by name
+var providers = map[string]Provider{}
+
+// providerOrder keeps registration order for stable listings
+var providerOrder []string
+
+// configuredOnce guards loading of the hosts declared in ~/.ss/config.yaml
+var configuredOnce sync.Once
+
+func init() {
+	RegisterProvider(NewGitHubProvider())
+	RegisterProvider(NewGitLabProvider())
+	RegisterProvider(NewBitbucketProvider())
+	RegisterProvider(NewSourcehutProvider())
+	RegisterProvider(NewCodebergProvider())
+	RegisterProvider(NewGiteaProvider())
+}
+
+// RegisterProvider adds a provider to the registry, replacing any provider
+// already registered under the same name
+func RegisterProvider(p Provider) {
+	if _, exists := providers[p.Name()]; !exists {
+		providerOrder = append(providerOrder, p.Name())
+	}
+	providers[p.Name()] = p
+}
+
+// GetProvider returns the provider registered for a site
+func GetProvider(site string) (Provider, bool) {
+	configuredOnce.Do(registerConfiguredHosts)
+	p, ok := providers[site]
+	return p, ok
+}
+
+// GetProviderByHost returns the provider whose base URL is on the given host
+// (e.g. "github.com" or "git.mycorp.net")
+func GetProviderByHost(host string) (Provider, bool) {
+	configuredOnce.Do(registerConfiguredHosts)
+	for _, name := range providerOrder {
+		if hostOf(providers[name].BaseURL()) == host {
+			return providers[name], true
+		}
+	}
+	return nil, false
+}
+
+// ProviderNames returns the names of all registered providers
+func ProviderNames() []string {
+	configuredOnce.Do(registerConfiguredHosts)
+	names := make([]string, len(providerOrder))
+	copy(names, providerOrder)
+	return names
+}
+
+// registerConfiguredHosts registers a provider for every self-hosted server
+// declared in ~/.ss/config.yaml
+func registerConfiguredHosts() {
+	for _, host := range auth.Hosts() {
+		p, err := NewHostProvider(host)
+		if err != nil {
+			sdk.Warning(fmt.Sprintf("Ignoring configured host: %v", err))
+			continue
+		}
+		RegisterProvider(p)
+	}
+}
+
+// NewHostProvider creates a provider for a self-hosted server
+func NewHostProvider(host auth.HostConfig) (Provider, error) {
+	baseURL := strings.TrimSuffix(host.BaseURL, "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("host %q has no base_url", host.Name)
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+
+	name := host.Name
+	if name == "" {
+		name = hostOf(baseURL)
+	}
+	apiURL := strings.TrimSuffix(host.APIURL, "/")
+
+	switch host.Type {
+	case "gitlab":
+		if apiURL == "" {
+			apiURL = baseURL + "/api/v4"
+		}
+		return &GitLabProvider{
+			gitProvider: gitProvider{name: name, baseURL: baseURL},
+			APIURL:      apiURL,
+			Token:       host.Token,
+		}, nil
+	case "gitea", "forgejo":
+		if apiURL == "" {
+			apiURL = baseURL + "/api/v1"
+		}
+		return &GiteaProvider{
+			gitProvider: gitProvider{name: name, baseURL: baseURL},
+			APIURL:      apiURL,
+			Token:       host.Token,
+		}, nil
+	case "github-enterprise":
+		if apiURL == "" {
+			apiURL = baseURL + "/api/v3"
+		}
+		return &GitHubProvider{
+			Site:   name,
+			Host:   baseURL,
+			APIURL: apiURL,
+			Token:  host.Token,
+		}, nil
+	default:
+		return nil, fmt.Errorf("host %q has unsupported type %q (supported: gitlab, gitea, forgejo, github-enterprise)", name, host.Type)
+	}
+}
+
+// maxPages bounds the pages read from a paginated API listing
+const maxPages = 100
+
+// lsRemote lists the refs of a repository URL with git ls-remote (see
+// FetchRefs); providers fall back to it when their API cannot help
+var lsRemote = FetchRefs
+
+// getJSON sends a request and decodes the JSON response into v
+func getJSON(req *http.Request, v interface{}) error {
+	_, err := getJSONHeader(req, v)
+	return err
+}
+
+// getJSONHeader is getJSON returning the response headers, which carry the
+// pagination links of list endpoints
+func getJSONHeader(req *http.Request, v interface{}) (http.Header, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{Path: req.URL.Path, Code: resp.StatusCode}
+	}
+
+	return resp.Header, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// statusError is returned for API responses other than 200 OK
+type statusError struct {
+	Path string // Request path
+	Code int    // HTTP status code
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("request to %s failed: %d", e.Path, e.Code)
+}
+
+// isNoAccess reports whether err is an API response saying that the
+// repository does not exist or may not be accessed (404 or 403)
+func isNoAccess(err error) bool {
+	var status *statusError
+	return errors.As(err, &status) && (status.Code == http.StatusNotFound || status.Code == http.StatusForbidden)
+}
+
+// hasRef reports whether refs resolve the ref name (see ResolveRef)
+func hasRef(refs []Ref, name string) bool {
+	_, err := ResolveRef(refs, name)
+	return err == nil
+}
+
+// escapeRefPath escapes a ref name for use in a URL path, keeping the
+// slashes of names like "feature/x"
+func escapeRefPath(name string) string {
+	return (&url.URL{Path: name}).EscapedPath()
+}
+
+// hostOf returns the host part of a URL, or the input if it cannot be parsed
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return strings.TrimPrefix(strings.TrimPrefix(rawURL, "https://"), "http://")
+	}
+	return u.Host
+}
+
+// gitProvider implements the behavior shared by hosts without an API
+// integration: refs via git ls-remote and unauthenticated downloads
+type gitProvider struct {
+	name    string
+	baseURL string
+}
+
+// Name returns the site key
+func (p *gitProvider) Name() string {
+	return p.name
+}
+
+// BaseURL returns the web URL of the host
+func (p *gitProvider) BaseURL() string {
+	return p.baseURL
+}
+
+// FetchRefs lists refs using git ls-remote
+func (p *gitProvider) FetchRefs(src *Source) ([]Ref, error) {
+	return lsRemote(src.URL)
+}
+
+// ArchiveURL returns the conventional /archive/<hash>.tar.gz URL
+func (p *gitProvider) ArchiveURL(src *Source, hash string) string {
+	return fmt.Sprintf("%s/archive/%s.tar.gz", src.URL, hash)
+}
+
+// Authenticate is a no-op for hosts without token support
+func (p *gitProvider) Authenticate(req *http.Request) {}
+
+// Download fetches the archive URL without authentication
+func (p *gitProvider) Download(src *Source, hash string, destPath string, opts DownloadOptions) error {
+	return downloadPublic(p.ArchiveURL(src, hash), destPath)
+}
+
+// CheckAccess issues a HEAD request against the repository URL
+func (p *gitProvider) CheckAccess(src *Source) (bool, error) {
+	resp, err := http.Head(src.URL)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return resp.StatusCode == http.StatusOK, nil
+}
@@ -0,0 +1,175 @@
+package degit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// BitbucketProvider implements Provider for bitbucket.org. When a token is
+// configured, refs go through the REST API and archives are downloaded with
+// it so private repositories work; otherwise git ls-remote and public
+// archives are used.
+type BitbucketProvider struct {
+	gitProvider
+	APIURL string // REST API root (e.g. "https://api.bitbucket.org/2.0")
+	Token  string // Repository, project or workspace access token
+}
+
+// NewBitbucketProvider creates a provider for bitbucket.org
+func NewBitbucketProvider() *BitbucketProvider {
+	return &BitbucketProvider{
+		gitProvider: gitProvider{name: "bitbucket", baseURL: "https://bitbucket.org"},
+		APIURL:      "https://api.bitbucket.org/2.0",
+		Token:       os.Getenv("BITBUCKET_TOKEN"),
+	}
+}
+
+// bitbucketRef is the shape of Bitbucket branch and tag API responses
+type bitbucketRef struct {
+	Name   string `json:"name"`
+	Target struct {
+		Hash string `json:"hash"`
+	} `json:"target"`
+}
+
+// bitbucketPage is a page of a Bitbucket list response
+type bitbucketPage struct {
+	Values []bitbucketRef `json:"values"`
+	Next   string         `json:"next"` // URL of the next page, empty on the last one
+}
+
+// Authenticate adds the access token as a bearer token
+func (p *BitbucketProvider) Authenticate(req *http.Request) {
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+}
+
+// repoPath returns the API path of the source's repository
+func (p *BitbucketProvider) repoPath(src *Source) string {
+	return fmt.Sprintf("/repositories/%s/%s", url.PathEscape(src.Owner), url.PathEscape(src.Repo))
+}
+
+// apiGetJSON issues an authenticated GET request against an API URL and
+// decodes the response into v
+func (p *BitbucketProvider) apiGetJSON(apiURL string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "ss-plugin-degit")
+	p.Authenticate(req)
+	return getJSON(req, v)
+}
+
+// FetchRefs lists refs via the API when a token is configured, falling back
+// to git ls-remote when the API fails or does not list the requested ref
+func (p *BitbucketProvider) FetchRefs(src *Source) ([]Ref, error) {
+	if p.Token == "" {
+		return lsRemote(src.URL)
+	}
+
+	refs, err := p.fetchAPIRefs(src)
+	if err != nil || !hasRef(refs, src.Ref) {
+		return lsRemote(src.URL)
+	}
+	return refs, nil
+}
+
+// fetchAPIRefs lists the main branch (as HEAD), branches and tags of the
+// source's repository, reading every page
+func (p *BitbucketProvider) fetchAPIRefs(src *Source) ([]Ref, error) {
+	repoURL := p.APIURL + p.repoPath(src)
+
+	var info struct {
+		MainBranch struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	if err := p.apiGetJSON(repoURL, &info); err != nil {
+		return nil, err
+	}
+
+	var refs []Ref
+
+	if info.MainBranch.Name != "" {
+		var head bitbucketRef
+		if err := p.apiGetJSON(repoURL+"/refs/branches/"+escapeRefPath(info.MainBranch.Name), &head); err != nil {
+			return nil, err
+		}
+		refs = append(refs, Ref{Type: "HEAD", Name: "HEAD", Hash: head.Target.Hash})
+	}
+
+	branches, err := p.listRefs(repoURL + "/refs/branches?pagelen=100")
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range branches {
+		refs = append(refs, Ref{Type: "branch", Name: b.Name, Hash: b.Target.Hash})
+	}
+
+	tags, err := p.listRefs(repoURL + "/refs/tags?pagelen=100")
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		refs = append(refs, Ref{Type: "tag", Name: t.Name, Hash: t.Target.Hash})
+	}
+
+	return refs, nil
+}
+
+// listRefs reads every page of a branch or tag listing, following the
+// "next" URL of each page. The token is only sent to the API itself.
+func (p *BitbucketProvider) listRefs(apiURL string) ([]bitbucketRef, error) {
+	var all []bitbucketRef
+	for n := 0; n < maxPages && apiURL != ""; n++ {
+		if !strings.HasPrefix(apiURL, p.APIURL+"/") {
+			return nil, fmt.Errorf("unexpected next page URL: %s", apiURL)
+		}
+
+		var page bitbucketPage
+		if err := p.apiGetJSON(apiURL, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Values...)
+		apiURL = page.Next
+	}
+	return all, nil
+}
+
+// ArchiveURL returns the Bitbucket archive URL
+func (p *BitbucketProvider) ArchiveURL(src *Source, hash string) string {
+	return fmt.Sprintf("%s/get/%s.tar.gz", src.URL, hash)
+}
+
+// Download fetches the Bitbucket archive, authenticated when a token is
+// configured
+func (p *BitbucketProvider) Download(src *Source, hash string, destPath string, opts DownloadOptions) error {
+	if p.Token == "" {
+		return downloadPublic(p.ArchiveURL(src, hash), destPath)
+	}
+	return downloadAuthenticated(p, p.ArchiveURL(src, hash), destPath)
+}
+
+// CheckAccess checks repository visibility via the API when a token is
+// configured
+func (p *BitbucketProvider) CheckAccess(src *Source) (bool, error) {
+	if p.Token == "" {
+		return p.gitProvider.CheckAccess(src)
+	}
+	var info struct {
+		UUID string `json:"uuid"`
+	}
+	if err := p.apiGetJSON(p.APIURL+p.repoPath(src), &info); err != nil {
+		if isNoAccess(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
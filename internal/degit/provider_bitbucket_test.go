@@ -0,0 +1,125 @@
+package degit
+
+import (
+	"net/http"
+	"testing"
+)
+
+// newBitbucketTestServer serves a repository with branches and tags on two
+// pages each
+func newBitbucketTestServer(t *testing.T) *apiServer {
+	s := newAPIServer(t)
+	repo := "/2.0/repositories/acme/tpl"
+
+	s.handle(repo, apiResponse{body: `{"uuid": "{1}", "mainbranch": {"name": "main"}}`})
+	s.handle(repo+"/refs/branches/main", apiResponse{body: `{"name": "main", "target": {"hash": "aaaaaaaa11111111"}}`})
+	s.handle(repo+"/refs/branches?pagelen=100", apiResponse{body: `{
+		"values": [{"name": "main", "target": {"hash": "aaaaaaaa11111111"}}],
+		"next": "` + s.URL + repo + `/refs/branches?pagelen=100&page=2"
+	}`})
+	s.handle(repo+"/refs/branches?pagelen=100&page=2", apiResponse{body: `{
+		"values": [{"name": "feature/x", "target": {"hash": "bbbbbbbb22222222"}}]
+	}`})
+	s.handle(repo+"/refs/tags?pagelen=100", apiResponse{body: `{
+		"values": [{"name": "v2.0.0", "target": {"hash": "cccccccc33333333"}}],
+		"next": "` + s.URL + repo + `/refs/tags?pagelen=100&page=2"
+	}`})
+	s.handle(repo+"/refs/tags?pagelen=100&page=2", apiResponse{body: `{
+		"values": [{"name": "v1.0.0", "target": {"hash": "dddddddd44444444"}}]
+	}`})
+	s.handle("/acme/tpl/get/aaaaaaaa11111111.tar.gz", apiResponse{body: "tarball"})
+	return s
+}
+
+// newBitbucketTestProvider creates a provider for a fake Bitbucket server
+func newBitbucketTestProvider(s *apiServer, token string) *BitbucketProvider {
+	return &BitbucketProvider{
+		gitProvider: gitProvider{name: "bitbucket", baseURL: s.URL},
+		APIURL:      s.URL + "/2.0",
+		Token:       token,
+	}
+}
+
+func TestBitbucketProviderFetchRefs(t *testing.T) {
+	s := newBitbucketTestServer(t)
+	p := newBitbucketTestProvider(s, "secret")
+	src := Source{Site: "bitbucket", Owner: "acme", Repo: "tpl", URL: s.URL + "/acme/tpl"}
+
+	checkRefs(t, p, src, []refCase{
+		{name: "main branch", ref: "HEAD", want: "aaaaaaaa11111111"},
+		{name: "branch on second page", ref: "feature/x", want: "bbbbbbbb22222222"},
+		{name: "tag on second page", ref: "v1.0.0", want: "dddddddd44444444"},
+		{name: "unknown ref", ref: "v0.1.0-only-in-git", want: lsRemoteRefs[2].Hash, lsRemote: true},
+	})
+	s.checkHeader(t, "Authorization", "Bearer secret")
+}
+
+func TestBitbucketProviderFetchRefsFallback(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		setup func(s *apiServer)
+	}{
+		{name: "no token", token: ""},
+		{name: "API error", token: "secret", setup: func(s *apiServer) {
+			s.handle("/2.0/repositories/acme/tpl", apiResponse{status: http.StatusForbidden})
+		}},
+		{name: "next page on another host", token: "secret", setup: func(s *apiServer) {
+			s.handle("/2.0/repositories/acme/tpl/refs/tags?pagelen=100", apiResponse{body: `{
+				"values": [], "next": "https://attacker.example/2.0/tags"
+			}`})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newBitbucketTestServer(t)
+			if tt.setup != nil {
+				tt.setup(s)
+			}
+			p := newBitbucketTestProvider(s, tt.token)
+			src := Source{Site: "bitbucket", Owner: "acme", Repo: "tpl", Ref: "HEAD", URL: s.URL + "/acme/tpl"}
+			calls := stubLsRemote(t)
+
+			refs, err := p.FetchRefs(&src)
+			if err != nil {
+				t.Fatalf("FetchRefs() error = %v", err)
+			}
+			if len(*calls) != 1 || refs[0] != lsRemoteRefs[0] {
+				t.Errorf("FetchRefs() = %v with %d ls-remote calls, want the ls-remote refs", refs, len(*calls))
+			}
+			if tt.token == "" && s.requestCount() > 0 {
+				t.Errorf("API called without a token")
+			}
+		})
+	}
+}
+
+func TestBitbucketProviderArchive(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		auth  string
+	}{
+		{name: "public", token: "", auth: ""},
+		{name: "with token", token: "secret", auth: "Bearer secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newBitbucketTestServer(t)
+			p := newBitbucketTestProvider(s, tt.token)
+			src := Source{Site: "bitbucket", Owner: "acme", Repo: "tpl", URL: s.URL + "/acme/tpl"}
+
+			if got, want := p.ArchiveURL(&src, "abc"), s.URL+"/acme/tpl/get/abc.tar.gz"; got != want {
+				t.Errorf("ArchiveURL() = %s, want %s", got, want)
+			}
+			checkDownload(t, p, src, "aaaaaaaa11111111", "tarball")
+			s.checkHeader(t, "Authorization", tt.auth)
+		})
+	}
+}
+
+func TestBitbucketProviderCheckAccess(t *testing.T) {
+	checkAccess(t, func(s *apiServer) Provider { return newBitbucketTestProvider(s, "secret") }, "/2.0/repositories/acme/tpl")
+}
@@ -0,0 +1,173 @@
+package degit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// GiteaProvider implements Provider for Gitea-family forges (Gitea, Forgejo,
+// Codeberg). When a token is configured, refs are listed through the REST
+// API; otherwise, or when the API cannot resolve a ref, git ls-remote is used.
+type GiteaProvider struct {
+	gitProvider
+	APIURL string // REST API root (e.g. "https://codeberg.org/api/v1")
+	Token  string // Access token for private repos
+}
+
+// NewGiteaProvider creates a provider for gitea.com
+func NewGiteaProvider() *GiteaProvider {
+	return &GiteaProvider{
+		gitProvider: gitProvider{name: "gitea", baseURL: "https://gitea.com"},
+		APIURL:      "https://gitea.com/api/v1",
+		Token:       os.Getenv("GITEA_TOKEN"),
+	}
+}
+
+// NewCodebergProvider creates a provider for codeberg.org
+func NewCodebergProvider() *GiteaProvider {
+	return &GiteaProvider{
+		gitProvider: gitProvider{name: "codeberg", baseURL: "https://codeberg.org"},
+		APIURL:      "https://codeberg.org/api/v1",
+		Token:       os.Getenv("CODEBERG_TOKEN"),
+	}
+}
+
+// giteaPageSize is the page size of list requests (the default maximum of
+// Gitea servers)
+const giteaPageSize = 50
+
+// Gitea API response types
+type giteaRepo struct {
+	DefaultBranch string `json:"default_branch"`
+}
+
+type giteaBranch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+type giteaTag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// Authenticate adds the access token using Gitea's "token" scheme
+func (p *GiteaProvider) Authenticate(req *http.Request) {
+	if p.Token != "" {
+		req.Header.Set("Authorization", "token "+p.Token)
+	}
+}
+
+// repoPath returns the API path of the source's repository
+func (p *GiteaProvider) repoPath(src *Source) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(src.Owner), url.PathEscape(src.Repo))
+}
+
+// apiGetJSON issues an authenticated API GET request and decodes the response into v
+func (p *GiteaProvider) apiGetJSON(path string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, p.APIURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "ss-plugin-degit")
+	p.Authenticate(req)
+	return getJSON(req, v)
+}
+
+// FetchRefs lists refs via the API when a token is configured, falling back
+// to git ls-remote when the API fails or does not list the requested ref
+func (p *GiteaProvider) FetchRefs(src *Source) ([]Ref, error) {
+	if p.Token == "" {
+		return lsRemote(src.URL)
+	}
+
+	refs, err := p.fetchAPIRefs(src)
+	if err != nil || !hasRef(refs, src.Ref) {
+		return lsRemote(src.URL)
+	}
+	return refs, nil
+}
+
+// fetchAPIRefs lists the default branch (as HEAD), branches and tags of
+// the source's repository, reading every page
+func (p *GiteaProvider) fetchAPIRefs(src *Source) ([]Ref, error) {
+	repoPath := p.repoPath(src)
+
+	var info giteaRepo
+	if err := p.apiGetJSON(repoPath, &info); err != nil {
+		return nil, err
+	}
+
+	var refs []Ref
+
+	if info.DefaultBranch != "" {
+		var head giteaBranch
+		if err := p.apiGetJSON(repoPath+"/branches/"+escapeRefPath(info.DefaultBranch), &head); err != nil {
+			return nil, err
+		}
+		refs = append(refs, Ref{Type: "HEAD", Name: "HEAD", Hash: head.Commit.ID})
+	}
+
+	for page := 1; page <= maxPages; page++ {
+		var branches []giteaBranch
+		if err := p.apiGetJSON(fmt.Sprintf("%s/branches?limit=%d&page=%d", repoPath, giteaPageSize, page), &branches); err != nil {
+			return nil, err
+		}
+		for _, b := range branches {
+			refs = append(refs, Ref{Type: "branch", Name: b.Name, Hash: b.Commit.ID})
+		}
+		if len(branches) < giteaPageSize {
+			break
+		}
+	}
+
+	for page := 1; page <= maxPages; page++ {
+		var tags []giteaTag
+		if err := p.apiGetJSON(fmt.Sprintf("%s/tags?limit=%d&page=%d", repoPath, giteaPageSize, page), &tags); err != nil {
+			return nil, err
+		}
+		for _, t := range tags {
+			refs = append(refs, Ref{Type: "tag", Name: t.Name, Hash: t.Commit.SHA})
+		}
+		if len(tags) < giteaPageSize {
+			break
+		}
+	}
+
+	return refs, nil
+}
+
+// ArchiveURL returns the Gitea archive URL (/archive/<ref>.tar.gz)
+func (p *GiteaProvider) ArchiveURL(src *Source, hash string) string {
+	return fmt.Sprintf("%s/archive/%s.tar.gz", src.URL, hash)
+}
+
+// Download fetches the archive through the API when a token is configured,
+// otherwise from the public archive URL
+func (p *GiteaProvider) Download(src *Source, hash string, destPath string, opts DownloadOptions) error {
+	if p.Token == "" {
+		return downloadPublic(p.ArchiveURL(src, hash), destPath)
+	}
+	apiURL := fmt.Sprintf("%s%s/archive/%s.tar.gz", p.APIURL, p.repoPath(src), hash)
+	return downloadAuthenticated(p, apiURL, destPath)
+}
+
+// CheckAccess checks repository visibility via the API. Only "not found"
+// and "forbidden" mean no access; other failures are returned.
+func (p *GiteaProvider) CheckAccess(src *Source) (bool, error) {
+	var info giteaRepo
+	if err := p.apiGetJSON(p.repoPath(src), &info); err != nil {
+		if isNoAccess(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,132 @@
+package degit
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// giteaRefs returns a JSON list of n branches or tags named prefix<i>
+func giteaRefs(prefix string, n int, commit string) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"name": "%s%d", "commit": {"id": "%x", "sha": "%x"}}`, prefix, i, i, i)
+	}
+	if commit != "" {
+		items = append(items, commit)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// newGiteaTestServer serves a repository with a full first page and a
+// short second page of branches and tags
+func newGiteaTestServer(t *testing.T) *apiServer {
+	s := newAPIServer(t)
+	repo := "/api/v1/repos/acme/tpl"
+
+	s.handle(repo, apiResponse{body: `{"default_branch": "trunk"}`})
+	s.handle(repo+"/branches/trunk", apiResponse{body: `{"name": "trunk", "commit": {"id": "aaaaaaaa11111111"}}`})
+	s.handle(repo+"/branches?limit=50&page=1", apiResponse{body: giteaRefs("branch-", giteaPageSize, "")})
+	s.handle(repo+"/branches?limit=50&page=2", apiResponse{body: giteaRefs("branch-", 0,
+		`{"name": "trunk", "commit": {"id": "aaaaaaaa11111111"}}, {"name": "feature/x", "commit": {"id": "bbbbbbbb22222222"}}`)})
+	s.handle(repo+"/tags?limit=50&page=1", apiResponse{body: giteaRefs("tag-", giteaPageSize, "")})
+	s.handle(repo+"/tags?limit=50&page=2", apiResponse{body: giteaRefs("tag-", 0, `{"name": "v1.0.0", "commit": {"sha": "dddddddd44444444"}}`)})
+	s.handle(repo+"/archive/aaaaaaaa11111111.tar.gz", apiResponse{body: "tarball"})
+	s.handle("/acme/tpl/archive/aaaaaaaa11111111.tar.gz", apiResponse{body: "public tarball"})
+	return s
+}
+
+// newGiteaTestProvider creates a provider for a fake Gitea server
+func newGiteaTestProvider(s *apiServer, token string) *GiteaProvider {
+	return &GiteaProvider{
+		gitProvider: gitProvider{name: "codeberg", baseURL: s.URL},
+		APIURL:      s.URL + "/api/v1",
+		Token:       token,
+	}
+}
+
+func TestGiteaProviderFetchRefs(t *testing.T) {
+	s := newGiteaTestServer(t)
+	p := newGiteaTestProvider(s, "secret")
+	src := Source{Site: "codeberg", Owner: "acme", Repo: "tpl", URL: s.URL + "/acme/tpl"}
+
+	checkRefs(t, p, src, []refCase{
+		{name: "default branch on second page", ref: "HEAD", want: "aaaaaaaa11111111"},
+		{name: "branch on first page", ref: "branch-7", want: "7"},
+		{name: "branch on second page", ref: "feature/x", want: "bbbbbbbb22222222"},
+		{name: "tag on second page", ref: "v1.0.0", want: "dddddddd44444444"},
+		{name: "unknown ref", ref: "v0.1.0-only-in-git", want: lsRemoteRefs[2].Hash, lsRemote: true},
+	})
+	s.checkHeader(t, "Authorization", "token secret")
+}
+
+func TestGiteaProviderFetchRefsFallback(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		setup func(s *apiServer)
+	}{
+		{name: "no token", token: ""},
+		{name: "API error", token: "secret", setup: func(s *apiServer) {
+			s.handle("/api/v1/repos/acme/tpl", apiResponse{status: http.StatusUnauthorized})
+		}},
+		{name: "default branch missing", token: "secret", setup: func(s *apiServer) {
+			s.handle("/api/v1/repos/acme/tpl/branches/trunk", apiResponse{status: http.StatusNotFound})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newGiteaTestServer(t)
+			if tt.setup != nil {
+				tt.setup(s)
+			}
+			p := newGiteaTestProvider(s, tt.token)
+			src := Source{Site: "codeberg", Owner: "acme", Repo: "tpl", Ref: "HEAD", URL: s.URL + "/acme/tpl"}
+			calls := stubLsRemote(t)
+
+			refs, err := p.FetchRefs(&src)
+			if err != nil {
+				t.Fatalf("FetchRefs() error = %v", err)
+			}
+			if len(*calls) != 1 || refs[0] != lsRemoteRefs[0] {
+				t.Errorf("FetchRefs() = %v with %d ls-remote calls, want the ls-remote refs", refs, len(*calls))
+			}
+			if tt.token == "" && s.requestCount() > 0 {
+				t.Errorf("API called without a token")
+			}
+		})
+	}
+}
+
+func TestGiteaProviderArchive(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{name: "public", token: "", want: "public tarball"},
+		{name: "with token", token: "secret", want: "tarball"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newGiteaTestServer(t)
+			p := newGiteaTestProvider(s, tt.token)
+			src := Source{Site: "codeberg", Owner: "acme", Repo: "tpl", URL: s.URL + "/acme/tpl"}
+
+			if got, want := p.ArchiveURL(&src, "abc"), s.URL+"/acme/tpl/archive/abc.tar.gz"; got != want {
+				t.Errorf("ArchiveURL() = %s, want %s", got, want)
+			}
+			checkDownload(t, p, src, "aaaaaaaa11111111", tt.want)
+			if tt.token != "" {
+				s.checkHeader(t, "Authorization", "token secret")
+			}
+		})
+	}
+}
+
+func TestGiteaProviderCheckAccess(t *testing.T) {
+	checkAccess(t, func(s *apiServer) Provider { return newGiteaTestProvider(s, "secret") }, "/api/v1/repos/acme/tpl")
+}
@@ -0,0 +1,331 @@
+package degit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	sdk "github.com/ssgohq/ss-plugin-sdk"
+
+	"github.com/ssgohq/ss-plugin-degit/internal/auth"
+)
+
+// GitHubProvider implements Provider for github.com and GitHub Enterprise
+// Server using the REST API, which works for both public and private repositories
+type GitHubProvider struct {
+	Site   string // Site key (e.g. "github")
+	Host   string // Web host (e.g. "https://github.com")
+	APIURL string // REST API root (e.g. "https://api.github.com")
+	Token  string // Overrides auth.GitHubToken when set
+}
+
+// NewGitHubProvider creates a provider for github.com
+func NewGitHubProvider() *GitHubProvider {
+	return &GitHubProvider{
+		Site:   "github",
+		Host:   "https://github.com",
+		APIURL: "https://api.github.com",
+	}
+}
+
+// Name returns the site key
+func (p *GitHubProvider) Name() string {
+	return p.Site
+}
+
+// BaseURL returns the web URL of the host
+func (p *GitHubProvider) BaseURL() string {
+	return p.Host
+}
+
+// token returns the configured token or, for github.com only, the one
+// resolved by auth
+func (p *GitHubProvider) token() string {
+	if p.Token != "" {
+		return p.Token
+	}
+	if hostOf(p.APIURL) != "api.github.com" {
+		return ""
+	}
+	return auth.GitHubToken()
+}
+
+// Authenticate adds the GitHub token as a bearer token
+func (p *GitHubProvider) Authenticate(req *http.Request) {
+	if token := p.token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// isProviderHost checks if a host belongs to this provider; auth headers are
+// only preserved across redirects to these hosts. github.com's download
+// hosts only belong to github.com itself, not to GitHub Enterprise servers.
+func (p *GitHubProvider) isProviderHost(host string) bool {
+	if host == hostOf(p.APIURL) || host == hostOf(p.Host) {
+		return true
+	}
+	if hostOf(p.Host) != "github.com" {
+		return false
+	}
+	return host == "codeload.github.com" || host == "objects.githubusercontent.com"
+}
+
+// newClient creates a client with a redirect handler that preserves auth
+// for same-provider redirects
+func (p *GitHubProvider) newClient() *http.Client {
+	return &http.Client{
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("too many redirects")
+			}
+			if p.isProviderHost(r.URL.Host) {
+				if auth := via[0].Header.Get("Authorization"); auth != "" {
+					r.Header.Set("Authorization", auth)
+				}
+			}
+			r.Header.Set("User-Agent", "ss-plugin-degit")
+			return nil
+		},
+	}
+}
+
+// apiGet issues an authenticated GET request against the REST API
+func (p *GitHubProvider) apiGet(path string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, p.APIURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "ss-plugin-degit")
+	p.Authenticate(req)
+	return p.newClient().Do(req)
+}
+
+// apiGetJSON issues an API GET request and decodes the JSON response into v
+func (p *GitHubProvider) apiGetJSON(path string, v interface{}) error {
+	_, err := p.apiGetJSONHeader(path, v)
+	return err
+}
+
+// apiGetJSONHeader is apiGetJSON returning the response headers
+func (p *GitHubProvider) apiGetJSONHeader(path string, v interface{}) (http.Header, error) {
+	resp, err := p.apiGet(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request to %s failed: %d", path, resp.StatusCode)
+	}
+
+	return resp.Header, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// listRefs reads every page of a branch or tag listing, following the
+// "next" link of the Link header
+func (p *GitHubProvider) listRefs(path string) ([]gitHubRef, error) {
+	var all []gitHubRef
+	for page := 1; page <= maxPages; page++ {
+		var items []gitHubRef
+		header, err := p.apiGetJSONHeader(fmt.Sprintf("%s?per_page=100&page=%d", path, page), &items)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if !strings.Contains(header.Get("Link"), `rel="next"`) {
+			break
+		}
+	}
+	return all, nil
+}
+
+// GitHub API response types
+type gitHubRepo struct {
+	DefaultBranch string `json:"default_branch"`
+}
+
+// gitHubRef is the shape of GitHub branch and tag API responses
+type gitHubRef struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// FetchRefs fetches refs using the GitHub API when a token is available,
+// falling back to git ls-remote otherwise or when the API fails or does not
+// list the requested ref
+func (p *GitHubProvider) FetchRefs(src *Source) ([]Ref, error) {
+	if p.token() == "" {
+		// No token, try git ls-remote
+		return lsRemote(src.URL)
+	}
+
+	refs, err := p.fetchAPIRefs(src)
+	if err != nil || !hasRef(refs, src.Ref) {
+		return lsRemote(src.URL)
+	}
+	return refs, nil
+}
+
+// fetchAPIRefs lists the default branch (as HEAD), branches and tags of
+// the source's repository, reading every page
+func (p *GitHubProvider) fetchAPIRefs(src *Source) ([]Ref, error) {
+	repoPath := fmt.Sprintf("/repos/%s/%s", src.Owner, src.Repo)
+	var refs []Ref
+
+	// Fetch default branch (HEAD)
+	var repoInfo gitHubRepo
+	if err := p.apiGetJSON(repoPath, &repoInfo); err != nil {
+		return nil, err
+	}
+
+	if repoInfo.DefaultBranch != "" {
+		var branchInfo gitHubRef
+		if err := p.apiGetJSON(repoPath+"/branches/"+escapeRefPath(repoInfo.DefaultBranch), &branchInfo); err != nil {
+			return nil, err
+		}
+		refs = append(refs, Ref{
+			Type: "HEAD",
+			Name: "HEAD",
+			Hash: branchInfo.Commit.SHA,
+		})
+	}
+
+	// Fetch branches
+	branches, err := p.listRefs(repoPath + "/branches")
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range branches {
+		refs = append(refs, Ref{
+			Type: "branch",
+			Name: b.Name,
+			Hash: b.Commit.SHA,
+		})
+	}
+
+	// Fetch tags
+	tags, err := p.listRefs(repoPath + "/tags")
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		refs = append(refs, Ref{
+			Type: "tag",
+			Name: t.Name,
+			Hash: t.Commit.SHA,
+		})
+	}
+
+	return refs, nil
+}
+
+// ArchiveURL returns the direct (web) tarball URL
+func (p *GitHubProvider) ArchiveURL(src *Source, hash string) string {
+	return fmt.Sprintf("%s/archive/%s.tar.gz", src.URL, hash)
+}
+
+// APIArchiveURL returns the API tarball URL used for private repositories
+func (p *GitHubProvider) APIArchiveURL(src *Source, ref string) string {
+	return fmt.Sprintf("%s/repos/%s/%s/tarball/%s", p.APIURL, src.Owner, src.Repo, ref)
+}
+
+// Download tries the API tarball endpoint first (works for both public and
+// private repos) and falls back to the direct archive URL
+func (p *GitHubProvider) Download(src *Source, hash string, destPath string, opts DownloadOptions) error {
+	err := p.downloadAPI(src, hash, destPath, opts)
+	if err == nil {
+		return nil
+	}
+
+	if opts.Verbose {
+		sdk.Warning(fmt.Sprintf("API download failed: %v", err))
+	}
+
+	// Always try direct URL as fallback (might work for public repos)
+	if opts.Verbose {
+		sdk.Info("Trying direct URL download...")
+	}
+	directErr := downloadPublic(p.ArchiveURL(src, hash), destPath)
+	if directErr == nil {
+		return nil
+	}
+
+	// Return original API error for private repos, direct error for public
+	if opts.Token != "" {
+		return fmt.Errorf("API download failed: %w (direct download also failed: %v)", err, directErr)
+	}
+	return directErr
+}
+
+// downloadAPI downloads a tarball using the API tarball endpoint
+func (p *GitHubProvider) downloadAPI(src *Source, hash string, destPath string, opts DownloadOptions) error {
+	apiURL := p.APIArchiveURL(src, hash)
+
+	if opts.Verbose {
+		sdk.Info(fmt.Sprintf("Requesting tarball from API: %s", apiURL))
+	}
+
+	// Create request
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers - use application/vnd.github+json for API
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "ss-plugin-degit")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	// Add authorization if token is available
+	p.Authenticate(req)
+	if opts.Verbose {
+		if req.Header.Get("Authorization") != "" {
+			sdk.Info("Using GitHub token for authentication")
+		} else {
+			sdk.Warning("No GitHub token found - private repos will not be accessible")
+		}
+	}
+
+	resp, err := p.newClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request tarball: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if opts.Verbose {
+		sdk.Info(fmt.Sprintf("Response status: %d", resp.StatusCode))
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("repository not found or not accessible (404)")
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("unauthorized: invalid or missing GitHub token (401)")
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("forbidden: check your GitHub token permissions (403)")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+
+	return saveResponse(resp, destPath)
+}
+
+// CheckAccess checks repository visibility via the API
+func (p *GitHubProvider) CheckAccess(src *Source) (bool, error) {
+	resp, err := p.apiGet(fmt.Sprintf("/repos/%s/%s", src.Owner, src.Repo))
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return resp.StatusCode == http.StatusOK, nil
+}
@@ -0,0 +1,128 @@
+package degit
+
+import (
+	"net/http"
+	"testing"
+)
+
+// newGitHubTestServer serves a repository with branches and tags on two
+// pages each
+func newGitHubTestServer(t *testing.T) *apiServer {
+	s := newAPIServer(t)
+	next := map[string]string{"Link": `<` + s.URL + `/api/repos/acme/tpl/branches?per_page=100&page=2>; rel="next"`}
+
+	s.handle("/api/repos/acme/tpl", apiResponse{body: `{"default_branch": "main"}`})
+	s.handle("/api/repos/acme/tpl/branches/main", apiResponse{body: `{"name": "main", "commit": {"sha": "aaaaaaaa11111111"}}`})
+	s.handle("/api/repos/acme/tpl/branches?per_page=100&page=1", apiResponse{
+		body:   `[{"name": "main", "commit": {"sha": "aaaaaaaa11111111"}}]`,
+		header: next,
+	})
+	s.handle("/api/repos/acme/tpl/branches?per_page=100&page=2", apiResponse{body: `[{"name": "feature/x", "commit": {"sha": "bbbbbbbb22222222"}}]`})
+	s.handle("/api/repos/acme/tpl/tags?per_page=100&page=1", apiResponse{
+		body:   `[{"name": "v2.0.0", "commit": {"sha": "cccccccc33333333"}}]`,
+		header: next,
+	})
+	s.handle("/api/repos/acme/tpl/tags?per_page=100&page=2", apiResponse{body: `[{"name": "v1.0.0", "commit": {"sha": "dddddddd44444444"}}]`})
+	s.handle("/api/repos/acme/tpl/tarball/aaaaaaaa11111111", apiResponse{body: "tarball"})
+	return s
+}
+
+func TestGitHubProviderFetchRefs(t *testing.T) {
+	s := newGitHubTestServer(t)
+	p := &GitHubProvider{Site: "github", Host: s.URL, APIURL: s.URL + "/api", Token: "secret"}
+	src := Source{Site: "github", Owner: "acme", Repo: "tpl", URL: s.URL + "/acme/tpl"}
+
+	checkRefs(t, p, src, []refCase{
+		{name: "default branch", ref: "HEAD", want: "aaaaaaaa11111111"},
+		{name: "branch on second page", ref: "feature/x", want: "bbbbbbbb22222222"},
+		{name: "tag on second page", ref: "v1.0.0", want: "dddddddd44444444"},
+		{name: "partial hash", ref: "cccccccc", want: "cccccccc33333333"},
+		{name: "unknown ref", ref: "v0.1.0-only-in-git", want: lsRemoteRefs[2].Hash, lsRemote: true},
+	})
+	s.checkHeader(t, "Authorization", "Bearer secret")
+}
+
+func TestGitHubProviderFetchRefsFallback(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		setup func(s *apiServer)
+	}{
+		{name: "no token", token: ""},
+		{name: "API error", token: "secret", setup: func(s *apiServer) {
+			s.handle("/api/repos/acme/tpl", apiResponse{status: http.StatusInternalServerError})
+		}},
+		{name: "tag page error", token: "secret", setup: func(s *apiServer) {
+			s.handle("/api/repos/acme/tpl/tags?per_page=100&page=2", apiResponse{status: http.StatusForbidden})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newGitHubTestServer(t)
+			if tt.setup != nil {
+				tt.setup(s)
+			}
+			p := &GitHubProvider{Site: "github", Host: s.URL, APIURL: s.URL + "/api", Token: tt.token}
+			src := Source{Site: "github", Owner: "acme", Repo: "tpl", Ref: "HEAD", URL: s.URL + "/acme/tpl"}
+			calls := stubLsRemote(t)
+
+			refs, err := p.FetchRefs(&src)
+			if err != nil {
+				t.Fatalf("FetchRefs() error = %v", err)
+			}
+			if len(*calls) != 1 || refs[0] != lsRemoteRefs[0] {
+				t.Errorf("FetchRefs() = %v with %d ls-remote calls, want the ls-remote refs", refs, len(*calls))
+			}
+			if tt.token == "" && s.requestCount() > 0 {
+				t.Errorf("API called without a token")
+			}
+		})
+	}
+}
+
+func TestGitHubProviderArchive(t *testing.T) {
+	s := newGitHubTestServer(t)
+	p := &GitHubProvider{Site: "github", Host: s.URL, APIURL: s.URL + "/api", Token: "secret"}
+	src := Source{Site: "github", Owner: "acme", Repo: "tpl", URL: s.URL + "/acme/tpl"}
+
+	if got, want := p.ArchiveURL(&src, "abc"), s.URL+"/acme/tpl/archive/abc.tar.gz"; got != want {
+		t.Errorf("ArchiveURL() = %s, want %s", got, want)
+	}
+	if got, want := p.APIArchiveURL(&src, "abc"), s.URL+"/api/repos/acme/tpl/tarball/abc"; got != want {
+		t.Errorf("APIArchiveURL() = %s, want %s", got, want)
+	}
+
+	checkDownload(t, p, src, "aaaaaaaa11111111", "tarball")
+	s.checkHeader(t, "Authorization", "Bearer secret")
+}
+
+func TestGitHubProviderIsProviderHost(t *testing.T) {
+	github := NewGitHubProvider()
+	enterprise := &GitHubProvider{Site: "ghe", Host: "https://ghe.example.com", APIURL: "https://ghe.example.com/api/v3"}
+
+	tests := []struct {
+		name string
+		p    *GitHubProvider
+		host string
+		want bool
+	}{
+		{name: "github.com", p: github, host: "github.com", want: true},
+		{name: "github.com API", p: github, host: "api.github.com", want: true},
+		{name: "github.com codeload", p: github, host: "codeload.github.com", want: true},
+		{name: "github.com objects", p: github, host: "objects.githubusercontent.com", want: true},
+		{name: "github.com other host", p: github, host: "example.com", want: false},
+		{name: "enterprise host", p: enterprise, host: "ghe.example.com", want: true},
+		{name: "enterprise to codeload", p: enterprise, host: "codeload.github.com", want: false},
+		{name: "enterprise to objects", p: enterprise, host: "objects.githubusercontent.com", want: false},
+		{name: "enterprise to github.com", p: enterprise, host: "github.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.isProviderHost(tt.host); got != tt.want {
+				t.Errorf("isProviderHost(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
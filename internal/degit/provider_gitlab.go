@@ -0,0 +1,163 @@
+package degit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GitLabProvider implements Provider for gitlab.com and self-hosted GitLab.
+// When a token is configured, refs and archives go through the REST API so
+// private projects work; otherwise git ls-remote and public archives are used.
+type GitLabProvider struct {
+	gitProvider
+	APIURL string // REST API root (e.g. "https://gitlab.com/api/v4")
+	Token  string // Personal or project access token
+}
+
+// NewGitLabProvider creates a provider for gitlab.com
+func NewGitLabProvider() *GitLabProvider {
+	return &GitLabProvider{
+		gitProvider: gitProvider{name: "gitlab", baseURL: "https://gitlab.com"},
+		APIURL:      "https://gitlab.com/api/v4",
+	}
+}
+
+// gitLabRef is the shape of GitLab branch and tag API responses
+type gitLabRef struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+// projectPath returns the API path of the source's project
+func (p *GitLabProvider) projectPath(src *Source) string {
+	return "/projects/" + url.PathEscape(src.Owner+"/"+src.Repo)
+}
+
+// apiGetJSON issues an authenticated API GET request and decodes the response into v
+func (p *GitLabProvider) apiGetJSON(path string, v interface{}) error {
+	_, err := p.apiGetJSONHeader(path, v)
+	return err
+}
+
+// apiGetJSONHeader is apiGetJSON returning the response headers
+func (p *GitLabProvider) apiGetJSONHeader(path string, v interface{}) (http.Header, error) {
+	req, err := http.NewRequest(http.MethodGet, p.APIURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "ss-plugin-degit")
+	p.Authenticate(req)
+	return getJSONHeader(req, v)
+}
+
+// Authenticate adds the access token as a PRIVATE-TOKEN header
+func (p *GitLabProvider) Authenticate(req *http.Request) {
+	if p.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", p.Token)
+	}
+}
+
+// FetchRefs lists refs via the API when a token is configured, falling back
+// to git ls-remote when the API fails or does not list the requested ref
+func (p *GitLabProvider) FetchRefs(src *Source) ([]Ref, error) {
+	if p.Token == "" {
+		return lsRemote(src.URL)
+	}
+
+	refs, err := p.fetchAPIRefs(src)
+	if err != nil || !hasRef(refs, src.Ref) {
+		return lsRemote(src.URL)
+	}
+	return refs, nil
+}
+
+// fetchAPIRefs lists the default branch (as HEAD), branches and tags of
+// the source's project, reading every page
+func (p *GitLabProvider) fetchAPIRefs(src *Source) ([]Ref, error) {
+	project := p.projectPath(src)
+
+	var info struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := p.apiGetJSON(project, &info); err != nil {
+		return nil, err
+	}
+
+	var refs []Ref
+
+	if info.DefaultBranch != "" {
+		var head gitLabRef
+		if err := p.apiGetJSON(project+"/repository/branches/"+url.PathEscape(info.DefaultBranch), &head); err != nil {
+			return nil, err
+		}
+		refs = append(refs, Ref{Type: "HEAD", Name: "HEAD", Hash: head.Commit.ID})
+	}
+
+	branches, err := p.listRefs(project + "/repository/branches")
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range branches {
+		refs = append(refs, Ref{Type: "branch", Name: b.Name, Hash: b.Commit.ID})
+	}
+
+	tags, err := p.listRefs(project + "/repository/tags")
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		refs = append(refs, Ref{Type: "tag", Name: t.Name, Hash: t.Commit.ID})
+	}
+
+	return refs, nil
+}
+
+// listRefs reads every page of a branch or tag listing, following the
+// X-Next-Page header
+func (p *GitLabProvider) listRefs(path string) ([]gitLabRef, error) {
+	var all []gitLabRef
+	page := "1"
+	for n := 0; n < maxPages && page != ""; n++ {
+		var refs []gitLabRef
+		header, err := p.apiGetJSONHeader(fmt.Sprintf("%s?per_page=100&page=%s", path, url.QueryEscape(page)), &refs)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, refs...)
+		page = header.Get("X-Next-Page")
+	}
+	return all, nil
+}
+
+// ArchiveURL returns the GitLab archive URL
+func (p *GitLabProvider) ArchiveURL(src *Source, hash string) string {
+	return fmt.Sprintf("%s/-/archive/%s/%s-%s.tar.gz", src.URL, hash, src.Repo, hash)
+}
+
+// Download fetches the archive through the API when a token is configured,
+// otherwise from the public archive URL
+func (p *GitLabProvider) Download(src *Source, hash string, destPath string, opts DownloadOptions) error {
+	if p.Token == "" {
+		return downloadPublic(p.ArchiveURL(src, hash), destPath)
+	}
+	apiURL := fmt.Sprintf("%s%s/repository/archive.tar.gz?sha=%s", p.APIURL, p.projectPath(src), url.QueryEscape(hash))
+	return downloadAuthenticated(p, apiURL, destPath)
+}
+
+// CheckAccess checks project visibility via the API. Only "not found" and
+// "forbidden" mean no access; other failures are returned.
+func (p *GitLabProvider) CheckAccess(src *Source) (bool, error) {
+	var project struct {
+		ID int `json:"id"`
+	}
+	if err := p.apiGetJSON(p.projectPath(src), &project); err != nil {
+		if isNoAccess(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
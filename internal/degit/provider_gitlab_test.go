@@ -0,0 +1,112 @@
+package degit
+
+import (
+	"net/http"
+	"testing"
+)
+
+// newGitLabTestServer serves a project with branches and tags on two pages
+// each
+func newGitLabTestServer(t *testing.T) *apiServer {
+	s := newAPIServer(t)
+	project := "/api/v4/projects/acme%2Ftpl"
+
+	s.handle(project, apiResponse{body: `{"id": 1, "default_branch": "main"}`})
+	s.handle(project+"/repository/branches/main", apiResponse{body: `{"name": "main", "commit": {"id": "aaaaaaaa11111111"}}`})
+	s.handle(project+"/repository/branches?per_page=100&page=1", apiResponse{
+		body:   `[{"name": "develop", "commit": {"id": "eeeeeeee55555555"}}]`,
+		header: map[string]string{"X-Next-Page": "2"},
+	})
+	s.handle(project+"/repository/branches?per_page=100&page=2", apiResponse{
+		body:   `[{"name": "main", "commit": {"id": "aaaaaaaa11111111"}}, {"name": "feature/x", "commit": {"id": "bbbbbbbb22222222"}}]`,
+		header: map[string]string{"X-Next-Page": ""},
+	})
+	s.handle(project+"/repository/tags?per_page=100&page=1", apiResponse{
+		body:   `[{"name": "v2.0.0", "commit": {"id": "cccccccc33333333"}}]`,
+		header: map[string]string{"X-Next-Page": "2"},
+	})
+	s.handle(project+"/repository/tags?per_page=100&page=2", apiResponse{body: `[{"name": "v1.0.0", "commit": {"id": "dddddddd44444444"}}]`})
+	s.handle(project+"/repository/archive.tar.gz?sha=aaaaaaaa11111111", apiResponse{body: "tarball"})
+	return s
+}
+
+// newGitLabTestProvider creates a provider for a fake GitLab server
+func newGitLabTestProvider(s *apiServer, token string) *GitLabProvider {
+	return &GitLabProvider{
+		gitProvider: gitProvider{name: "gitlab", baseURL: s.URL},
+		APIURL:      s.URL + "/api/v4",
+		Token:       token,
+	}
+}
+
+func TestGitLabProviderFetchRefs(t *testing.T) {
+	s := newGitLabTestServer(t)
+	p := newGitLabTestProvider(s, "secret")
+	src := Source{Site: "gitlab", Owner: "acme", Repo: "tpl", URL: s.URL + "/acme/tpl"}
+
+	checkRefs(t, p, src, []refCase{
+		{name: "default branch on second page", ref: "HEAD", want: "aaaaaaaa11111111"},
+		{name: "branch on second page", ref: "feature/x", want: "bbbbbbbb22222222"},
+		{name: "tag on second page", ref: "v1.0.0", want: "dddddddd44444444"},
+		{name: "unknown ref", ref: "v0.1.0-only-in-git", want: lsRemoteRefs[2].Hash, lsRemote: true},
+	})
+	s.checkHeader(t, "PRIVATE-TOKEN", "secret")
+}
+
+func TestGitLabProviderFetchRefsFallback(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		setup func(s *apiServer)
+	}{
+		{name: "no token", token: ""},
+		{name: "project not found", token: "secret", setup: func(s *apiServer) {
+			s.handle("/api/v4/projects/acme%2Ftpl", apiResponse{status: http.StatusNotFound})
+		}},
+		{name: "branch page error", token: "secret", setup: func(s *apiServer) {
+			s.handle("/api/v4/projects/acme%2Ftpl/repository/branches?per_page=100&page=2", apiResponse{status: http.StatusInternalServerError})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newGitLabTestServer(t)
+			if tt.setup != nil {
+				tt.setup(s)
+			}
+			p := newGitLabTestProvider(s, tt.token)
+			src := Source{Site: "gitlab", Owner: "acme", Repo: "tpl", Ref: "HEAD", URL: s.URL + "/acme/tpl"}
+			calls := stubLsRemote(t)
+
+			refs, err := p.FetchRefs(&src)
+			if err != nil {
+				t.Fatalf("FetchRefs() error = %v", err)
+			}
+			if len(*calls) != 1 || refs[0] != lsRemoteRefs[0] {
+				t.Errorf("FetchRefs() = %v with %d ls-remote calls, want the ls-remote refs", refs, len(*calls))
+			}
+			if tt.token == "" && s.requestCount() > 0 {
+				t.Errorf("API called without a token")
+			}
+		})
+	}
+}
+
+func TestGitLabProviderArchive(t *testing.T) {
+	s := newGitLabTestServer(t)
+	src := Source{Site: "gitlab", Owner: "acme", Repo: "tpl", URL: s.URL + "/acme/tpl"}
+
+	p := newGitLabTestProvider(s, "")
+	if got, want := p.ArchiveURL(&src, "abc"), s.URL+"/acme/tpl/-/archive/abc/tpl-abc.tar.gz"; got != want {
+		t.Errorf("ArchiveURL() = %s, want %s", got, want)
+	}
+
+	// With a token, archives come from the API
+	p = newGitLabTestProvider(s, "secret")
+	checkDownload(t, p, src, "aaaaaaaa11111111", "tarball")
+	s.checkHeader(t, "PRIVATE-TOKEN", "secret")
+}
+
+func TestGitLabProviderCheckAccess(t *testing.T) {
+	checkAccess(t, func(s *apiServer) Provider { return newGitLabTestProvider(s, "secret") }, "/api/v4/projects/acme%2Ftpl")
+}
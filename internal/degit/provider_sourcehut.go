@@ -0,0 +1,14 @@
+package degit
+
+// SourcehutProvider implements Provider for git.sr.ht
+//
+// sourcehut uses the conventional /archive/<hash>.tar.gz layout, so all
+// behavior comes from gitProvider.
+type SourcehutProvider struct {
+	gitProvider
+}
+
+// NewSourcehutProvider creates a provider for git.sr.ht
+func NewSourcehutProvider() *SourcehutProvider {
+	return &SourcehutProvider{gitProvider{name: "git.sr.ht", baseURL: "https://git.sr.ht"}}
+}
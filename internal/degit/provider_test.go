@@ -0,0 +1,214 @@
+package degit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// apiResponse is a canned response of a fake API server
+type apiResponse struct {
+	status int               // Defaults to 200
+	body   string            // Response body
+	header map[string]string // Response headers
+}
+
+// apiServer is a fake host API answering requests by request URI (escaped
+// path and query) and recording the requests it received
+type apiServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	responses map[string]apiResponse
+	requests  []*http.Request
+}
+
+// newAPIServer starts a fake API server, closed when the test ends
+func newAPIServer(t *testing.T) *apiServer {
+	t.Helper()
+	s := &apiServer{responses: make(map[string]apiResponse)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		resp, ok := s.responses[r.RequestURI]
+		s.mu.Unlock()
+
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		for key, value := range resp.header {
+			w.Header().Set(key, value)
+		}
+		if resp.status != 0 {
+			w.WriteHeader(resp.status)
+		}
+		_, _ = w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// handle sets the response to a request URI
+func (s *apiServer) handle(uri string, resp apiResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[uri] = resp
+}
+
+// checkHeader fails the test unless every request received had the header
+func (s *apiServer) checkHeader(t *testing.T, key string, want string) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) == 0 {
+		t.Fatalf("no requests received")
+	}
+	for _, r := range s.requests {
+		if got := r.Header.Get(key); got != want {
+			t.Errorf("%s %s: %s header = %q, want %q", r.Method, r.RequestURI, key, got, want)
+		}
+	}
+}
+
+// requestCount returns the number of requests received
+func (s *apiServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// lsRemoteRefs are the refs the stubbed git ls-remote returns
+var lsRemoteRefs = []Ref{
+	{Type: "HEAD", Name: "HEAD", Hash: "1111111111111111111111111111111111111111"},
+	{Type: "branch", Name: "main", Hash: "1111111111111111111111111111111111111111"},
+	{Type: "tag", Name: "v0.1.0-only-in-git", Hash: "2222222222222222222222222222222222222222"},
+}
+
+// stubLsRemote replaces git ls-remote for the rest of the test and returns
+// the URLs it is called with
+func stubLsRemote(t *testing.T) *[]string {
+	t.Helper()
+	var calls []string
+	original := lsRemote
+	lsRemote = func(url string) ([]Ref, error) {
+		calls = append(calls, url)
+		return lsRemoteRefs, nil
+	}
+	t.Cleanup(func() { lsRemote = original })
+	return &calls
+}
+
+// refCase is a ref a provider should resolve, and whether resolving it
+// should fall back to git ls-remote
+type refCase struct {
+	name     string
+	ref      string
+	want     string
+	lsRemote bool
+}
+
+// checkRefs resolves each case's ref through p.FetchRefs
+func checkRefs(t *testing.T, p Provider, src Source, tests []refCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := stubLsRemote(t)
+
+			s := src
+			s.Ref = tt.ref
+			refs, err := p.FetchRefs(&s)
+			if err != nil {
+				t.Fatalf("FetchRefs() error = %v", err)
+			}
+			got, err := ResolveRef(refs, tt.ref)
+			if err != nil {
+				t.Fatalf("ResolveRef(%q) error = %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveRef(%q) = %s, want %s", tt.ref, got, tt.want)
+			}
+			if called := len(*calls) > 0; called != tt.lsRemote {
+				t.Errorf("git ls-remote called = %v, want %v", called, tt.lsRemote)
+			}
+			if tt.lsRemote && (*calls)[0] != src.URL {
+				t.Errorf("git ls-remote URL = %s, want %s", (*calls)[0], src.URL)
+			}
+		})
+	}
+}
+
+// checkDownload downloads an archive through p and checks its contents
+func checkDownload(t *testing.T, p Provider, src Source, hash string, want string) {
+	t.Helper()
+	dest := filepath.Join(t.TempDir(), "archive.tar.gz")
+	if err := p.Download(&src, hash, dest, DownloadOptions{}); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("downloaded %q, want %q", data, want)
+	}
+}
+
+func TestGetProvider(t *testing.T) {
+	for _, name := range []string{"github", "gitlab", "bitbucket", "git.sr.ht", "codeberg", "gitea"} {
+		p, ok := GetProvider(name)
+		if !ok {
+			t.Errorf("GetProvider(%q) not registered", name)
+			continue
+		}
+		if p.Name() != name {
+			t.Errorf("GetProvider(%q).Name() = %q", name, p.Name())
+		}
+	}
+
+	if p, ok := GetProviderByHost("gitlab.com"); !ok || p.Name() != "gitlab" {
+		t.Errorf("GetProviderByHost(gitlab.com) = %v, %v", p, ok)
+	}
+}
+
+// checkAccess checks that p.CheckAccess reports no access only for 404 and
+// 403 responses to repoURI, and returns other failures
+func checkAccess(t *testing.T, newProvider func(s *apiServer) Provider, repoURI string) {
+	t.Helper()
+	tests := []struct {
+		name    string
+		status  int
+		closed  bool // Whether the server is down
+		want    bool
+		wantErr bool
+	}{
+		{name: "accessible", status: http.StatusOK, want: true},
+		{name: "not found", status: http.StatusNotFound},
+		{name: "forbidden", status: http.StatusForbidden},
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: true},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true},
+		{name: "network error", closed: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newAPIServer(t)
+			s.handle(repoURI, apiResponse{status: tt.status, body: `{"id": 1}`})
+			p := newProvider(s)
+			if tt.closed {
+				s.Close()
+			}
+
+			src := Source{Owner: "acme", Repo: "tpl", URL: s.URL + "/acme/tpl"}
+			got, err := p.CheckAccess(&src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckAccess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CheckAccess() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,10 @@
 package degit
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"os/exec"
 	"regexp"
 	"strings"
-
-	"github.com/ssgohq/ss-plugin-degit/internal/auth"
 )
 
 // Ref represents a git reference (branch, tag, or commit)
@@ -32,172 +28,10 @@ func FetchRefs(url string) ([]Ref, error) {
 	return parseGitLsRemoteOutput(string(output))
 }
 
-// FetchRefsWithToken fetches refs using GitHub API (for private repos)
+// FetchRefsWithToken fetches refs through the source's provider, which uses
+// the host API (and token) where supported and git ls-remote otherwise
 func FetchRefsWithToken(src *Source) ([]Ref, error) {
-	if src.Site != "github" {
-		// Fall back to git ls-remote for non-GitHub
-		return FetchRefs(src.URL)
-	}
-
-	token := auth.GitHubToken()
-	if token == "" {
-		// No token, try git ls-remote
-		return FetchRefs(src.URL)
-	}
-
-	var refs []Ref
-
-	// Fetch default branch (HEAD)
-	repoInfo, err := fetchGitHubRepoInfo(src)
-	if err != nil {
-		// Fall back to git ls-remote
-		return FetchRefs(src.URL)
-	}
-
-	// Get default branch commit
-	defaultBranch := repoInfo.DefaultBranch
-	if defaultBranch == "" {
-		defaultBranch = "main"
-	}
-
-	branchInfo, err := fetchGitHubBranchInfo(src, defaultBranch)
-	if err == nil {
-		refs = append(refs, Ref{
-			Type: "HEAD",
-			Name: "HEAD",
-			Hash: branchInfo.Commit.SHA,
-		})
-		refs = append(refs, Ref{
-			Type: "branch",
-			Name: defaultBranch,
-			Hash: branchInfo.Commit.SHA,
-		})
-	}
-
-	// Fetch branches
-	branches, _ := fetchGitHubBranches(src)
-	for _, b := range branches {
-		// Skip if already added
-		if b.Name == defaultBranch {
-			continue
-		}
-		refs = append(refs, Ref{
-			Type: "branch",
-			Name: b.Name,
-			Hash: b.Commit.SHA,
-		})
-	}
-
-	// Fetch tags
-	tags, _ := fetchGitHubTags(src)
-	for _, t := range tags {
-		refs = append(refs, Ref{
-			Type: "tag",
-			Name: t.Name,
-			Hash: t.Commit.SHA,
-		})
-	}
-
-	if len(refs) == 0 {
-		return nil, fmt.Errorf("could not fetch any refs from GitHub API")
-	}
-
-	return refs, nil
-}
-
-// GitHub API response types
-type gitHubRepo struct {
-	DefaultBranch string `json:"default_branch"`
-}
-
-type gitHubBranch struct {
-	Name   string `json:"name"`
-	Commit struct {
-		SHA string `json:"sha"`
-	} `json:"commit"`
-}
-
-type gitHubTag struct {
-	Name   string `json:"name"`
-	Commit struct {
-		SHA string `json:"sha"`
-	} `json:"commit"`
-}
-
-func fetchGitHubRepoInfo(src *Source) (*gitHubRepo, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", src.Owner, src.Repo)
-	resp, err := auth.GitHubRequest(http.MethodGet, url)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch repo info: %d", resp.StatusCode)
-	}
-
-	var repo gitHubRepo
-	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
-		return nil, err
-	}
-	return &repo, nil
-}
-
-func fetchGitHubBranchInfo(src *Source, branch string) (*gitHubBranch, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/branches/%s", src.Owner, src.Repo, branch)
-	resp, err := auth.GitHubRequest(http.MethodGet, url)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch branch info: %d", resp.StatusCode)
-	}
-
-	var b gitHubBranch
-	if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
-		return nil, err
-	}
-	return &b, nil
-}
-
-func fetchGitHubBranches(src *Source) ([]gitHubBranch, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/branches?per_page=100", src.Owner, src.Repo)
-	resp, err := auth.GitHubRequest(http.MethodGet, url)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch branches: %d", resp.StatusCode)
-	}
-
-	var branches []gitHubBranch
-	if err := json.NewDecoder(resp.Body).Decode(&branches); err != nil {
-		return nil, err
-	}
-	return branches, nil
-}
-
-func fetchGitHubTags(src *Source) ([]gitHubTag, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/tags?per_page=100", src.Owner, src.Repo)
-	resp, err := auth.GitHubRequest(http.MethodGet, url)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch tags: %d", resp.StatusCode)
-	}
-
-	var tags []gitHubTag
-	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
-		return nil, err
-	}
-	return tags, nil
+	return src.Provider().FetchRefs(src)
 }
 
 // parseGitLsRemoteOutput parses the output of git ls-remote
@@ -0,0 +1,115 @@
+package degit
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	sdk "github.com/ssgohq/ss-plugin-sdk"
+
+	"github.com/ssgohq/ss-plugin-degit/internal/auth"
+)
+
+// script is the command of a "run" action and the template it came from
+type script struct {
+	command string
+	source  *Source
+	dir     string // Directory of the template, relative to the scaffold root
+}
+
+// executeRunAction executes a run action. The command is only queued: it
+// runs once all files are in the destination (see Degit.runScripts).
+func executeRunAction(action Action, degitInst *Degit) error {
+	if action.Command == "" {
+		return fmt.Errorf("run action requires 'command' field")
+	}
+
+	degitInst.scripts = append(degitInst.scripts, script{command: action.Command, source: degitInst.source, dir: degitInst.dir})
+	return nil
+}
+
+// runScripts runs the queued commands in dest, in order. Commands of
+// templates that are not trusted are skipped unless scripts are allowed.
+func (d *Degit) runScripts(dest string) error {
+	var skipped []string
+	for _, s := range d.scripts {
+		if !d.options.AllowScripts && !isTrusted(s.source) {
+			skipped = append(skipped, s.command)
+			continue
+		}
+
+		sdk.Info(fmt.Sprintf("Running: %s", s.command))
+		if err := runCommand(s.command, filepath.Join(dest, filepath.FromSlash(s.dir))); err != nil {
+			return fmt.Errorf("command %q failed: %w", s.command, err)
+		}
+	}
+	d.scripts = nil
+
+	if len(skipped) > 0 {
+		sdk.Warning("Skipped template commands (use --allow-scripts or add the owner to trusted_owners in ~/.ss/config.yaml):")
+		for _, command := range skipped {
+			sdk.Warning("  " + command)
+		}
+	}
+
+	return nil
+}
+
+// isTrusted reports whether the owner of src is listed in trusted_owners.
+// Entries are "site/owner"; a bare "owner" means a GitHub owner. Archive
+// URLs and local sources are never trusted through the list.
+func isTrusted(src *Source) bool {
+	if src == nil || src.Owner == "" || src.IsArchive() || src.IsLocal() {
+		return false
+	}
+
+	for _, entry := range auth.TrustedOwners() {
+		site, owner, found := strings.Cut(entry, "/")
+		if !found {
+			site, owner = "github", entry
+		}
+		if strings.EqualFold(site, src.Site) && strings.EqualFold(owner, src.Owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// runCommand runs a shell command in dir, logging its output line by line
+func runCommand(command string, dir string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Dir = dir
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	if err := cmd.Start(); err != nil {
+		_ = r.Close()
+		_ = w.Close()
+		return err
+	}
+	// The command holds its own copy of the write end
+	_ = w.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		sdk.Info(scanner.Text())
+	}
+	_ = r.Close()
+
+	return cmd.Wait()
+}
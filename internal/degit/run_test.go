@@ -0,0 +1,53 @@
+package degit
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRunScripts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands are written for sh")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeTestFile(t, filepath.Join(home, ".ss", "config.yaml"),
+		"trusted_owners:\n  - acme\n  - gitlab/team\n  - url/example.com\n  - local/me\n")
+
+	tests := []struct {
+		name   string
+		source *Source
+		allow  bool // --allow-scripts
+		want   bool // Whether the command runs
+	}{
+		{name: "untrusted owner", source: &Source{Site: "github", Owner: "other", Repo: "tpl"}},
+		{name: "untrusted owner with --allow-scripts", source: &Source{Site: "github", Owner: "other", Repo: "tpl"}, allow: true, want: true},
+		{name: "bare entry trusts a GitHub owner", source: &Source{Site: "github", Owner: "acme", Repo: "tpl"}, want: true},
+		{name: "owners compare case-insensitively", source: &Source{Site: "github", Owner: "ACME", Repo: "tpl"}, want: true},
+		{name: "bare entry does not trust other sites", source: &Source{Site: "gitlab", Owner: "acme", Repo: "tpl"}},
+		{name: "site entry", source: &Source{Site: "gitlab", Owner: "team", Repo: "tpl"}, want: true},
+		{name: "site entry does not trust GitHub", source: &Source{Site: "github", Owner: "team", Repo: "tpl"}},
+		{name: "archive URL is never trusted", source: &Source{Site: "url", Owner: "example.com", Repo: "tpl.tar.gz"}},
+		{name: "local source is never trusted", source: &Source{Site: "local", Owner: "me", Repo: "tpl", Path: "/src/tpl"}},
+		{name: "local source with --allow-scripts", source: &Source{Site: "local", Repo: "tpl", Path: "/src/tpl"}, allow: true, want: true},
+		{name: "unknown source", source: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := t.TempDir()
+			d := New(Options{AllowScripts: tt.allow})
+			d.scripts = []script{{command: "echo ran > ran.txt", source: tt.source}}
+
+			if err := d.runScripts(dest); err != nil {
+				t.Fatalf("runScripts() error = %v", err)
+			}
+			_, err := os.Stat(filepath.Join(dest, "ran.txt"))
+			if ran := err == nil; ran != tt.want {
+				t.Errorf("command ran = %v, want %v", ran, tt.want)
+			}
+		})
+	}
+}
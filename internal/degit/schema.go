@@ -0,0 +1,148 @@
+package degit
+
+//go:generate go run ../schemagen -o ../../degit.schema.json
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaURL is where the JSON Schema of the manifest is published
+const SchemaURL = "https://raw.githubusercontent.com/ssgohq/ss-plugin-degit/main/degit.schema.json"
+
+// ActionNames are the actions a manifest can use
+var ActionNames = []string{"clone", "remove", "rename", "move", "copy", "replace", "patch", "prompt", "run"}
+
+// actionRequired lists the fields each action needs
+var actionRequired = map[string][]string{
+	"clone":   {"src"},
+	"remove":  {"files"},
+	"rename":  {"from", "to"},
+	"move":    {"from", "to"},
+	"copy":    {"from", "to"},
+	"replace": {"search"},
+	"patch":   {"patch"},
+	"prompt":  {"name"},
+	"run":     {"command"},
+}
+
+// fieldSchemas override the schema derived from the Go type of a field,
+// keyed by "type.field"
+var fieldSchemas = map[string]map[string]interface{}{
+	"action.action": {"type": "string", "enum": ActionNames},
+	"action.type":   {"type": "string", "enum": []string{PromptText, PromptSelect, PromptConfirm, PromptMultiSelect}},
+	"action.files": {"oneOf": []interface{}{
+		map[string]interface{}{"type": "string"},
+		map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+	}},
+	"manifest.version": {"type": "integer", "minimum": 1, "maximum": ManifestVersion},
+}
+
+// schemaField is a manifest field, named by the json tag of a struct field
+type schemaField struct {
+	name     string
+	typ      reflect.Type
+	required bool
+}
+
+// schemaFields returns the manifest fields of a struct type. Fields without
+// omitempty are required.
+func schemaFields(t reflect.Type) []schemaField {
+	var fields []schemaField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, schemaField{
+			name:     name,
+			typ:      f.Type,
+			required: !strings.Contains(opts, "omitempty"),
+		})
+	}
+	return fields
+}
+
+// manifestSchema returns the JSON Schema of the manifest, generated from the
+// Manifest, Action and Variable types
+func manifestSchema() map[string]interface{} {
+	defs := make(map[string]interface{})
+	actions := typeSchema(reflect.TypeOf([]Action{}), defs)
+	object := typeSchema(reflect.TypeOf(Manifest{}), defs)
+
+	return map[string]interface{}{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         SchemaURL,
+		"title":       "degit manifest",
+		"description": "Variables and actions of a degit template (degit.json, degit.jsonc or degit.yaml)",
+		"oneOf":       []interface{}{actions, object},
+		"$defs":       defs,
+	}
+}
+
+// Schema returns the JSON Schema of the manifest, as published in
+// degit.schema.json
+func Schema() ([]byte, error) {
+	data, err := json.MarshalIndent(manifestSchema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// typeSchema returns the schema of a Go type. Structs are added to defs
+// and referenced.
+func typeSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		name := strings.ToLower(t.Name())
+		if _, ok := defs[name]; !ok {
+			defs[name] = structSchema(t, defs)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	}
+	// interface{} accepts any value
+	return map[string]interface{}{}
+}
+
+// structSchema returns the object schema of a struct type
+func structSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	for _, f := range schemaFields(t) {
+		schema, ok := fieldSchemas[strings.ToLower(t.Name())+"."+f.name]
+		if !ok {
+			schema = typeSchema(f.typ, defs)
+		}
+		properties[f.name] = schema
+		if f.required {
+			required = append(required, f.name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -3,6 +3,7 @@ package degit
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -16,14 +17,7 @@ type Source struct {
 	Subdir string // subdirectory path (optional)
 	URL    string // HTTPS URL
 	SSH    string // SSH URL
-}
-
-// supportedHosts lists the supported git hosting platforms
-var supportedHosts = map[string]bool{
-	"github":    true,
-	"gitlab":    true,
-	"bitbucket": true,
-	"git.sr.ht": true,
+	Path   string // Repository root on disk (local sources only)
 }
 
 // sourceRegex parses repository source strings in various formats:
@@ -31,10 +25,17 @@ var supportedHosts = map[string]bool{
 // - github:user/repo
 // - gitlab:user/repo
 // - bitbucket:user/repo
+// - codeberg:user/repo
+// - gitea:user/repo
 // - https://github.com/user/repo
 // - git@github.com:user/repo
+// - https://git.mycorp.net/user/repo (hosts declared in ~/.ss/config.yaml)
 // - user/repo#branch
 // - user/repo/subdir#ref
+//
+// Local paths (./templates/api, /srv/templates/repo#v2, file:///srv/repo)
+// are handled by parseLocalSource and direct archive URLs
+// (https://example.com/starter-1.4.tar.gz) by parseArchiveSource instead.
 var sourceRegex = regexp.MustCompile(
 	`^(?:(?:https:\/\/)?([^:/]+\.[^:/]+)\/|git@([^:/]+)[:/]|([^/]+):)?` +
 		`([^/\s]+)\/([^/\s#]+)(?:((?:\/[^/\s#]+)+))?(?:\/)?(?:#(.+))?$`,
@@ -42,30 +43,45 @@ var sourceRegex = regexp.MustCompile(
 
 // ParseSource parses a repository source string into a Source struct
 func ParseSource(src string) (*Source, error) {
+	if isLocalSource(src) {
+		return parseLocalSource(src)
+	}
+	if isArchiveURL(src) {
+		return parseArchiveSource(src)
+	}
+
 	match := sourceRegex.FindStringSubmatch(src)
 	if match == nil {
 		return nil, fmt.Errorf("could not parse source: %s", src)
 	}
 
 	// Determine the site (host)
-	site := match[1]
-	if site == "" {
-		site = match[2]
-	}
-	if site == "" {
-		site = match[3]
-	}
-	if site == "" {
-		site = "github"
+	host := match[1]
+	if host == "" {
+		host = match[2]
 	}
 
-	// Remove common TLD suffixes
-	site = strings.TrimSuffix(site, ".com")
-	site = strings.TrimSuffix(site, ".org")
-
-	// Check if the site is supported
-	if !supportedHosts[site] {
-		return nil, fmt.Errorf("unsupported host: %s (supported: github, gitlab, bitbucket, git.sr.ht)", site)
+	// Full hosts (https://host/... or git@host:...) may belong to a
+	// configured self-hosted server
+	provider, ok := GetProviderByHost(host)
+	if !ok {
+		site := host
+		if site == "" {
+			site = match[3]
+		}
+		if site == "" {
+			site = "github"
+		}
+
+		// Remove common TLD suffixes
+		site = strings.TrimSuffix(site, ".com")
+		site = strings.TrimSuffix(site, ".org")
+
+		// Check if the site is supported
+		provider, ok = GetProvider(site)
+		if !ok {
+			return nil, fmt.Errorf("unsupported host: %s (supported: %s)", site, strings.Join(ProviderNames(), ", "))
+		}
 	}
 
 	owner := match[4]
@@ -77,12 +93,11 @@ func ParseSource(src string) (*Source, error) {
 	}
 
 	// Build URLs
-	domain := getDomain(site)
-	url := fmt.Sprintf("https://%s/%s/%s", domain, owner, repo)
-	ssh := fmt.Sprintf("git@%s:%s/%s", domain, owner, repo)
+	url := fmt.Sprintf("%s/%s/%s", provider.BaseURL(), owner, repo)
+	ssh := fmt.Sprintf("git@%s:%s/%s", hostOf(provider.BaseURL()), owner, repo)
 
 	return &Source{
-		Site:   site,
+		Site:   provider.Name(),
 		Owner:  owner,
 		Repo:   repo,
 		Ref:    ref,
@@ -92,25 +107,25 @@ func ParseSource(src string) (*Source, error) {
 	}, nil
 }
 
-// getDomain returns the full domain for a site
-func getDomain(site string) string {
-	switch site {
-	case "github":
-		return "github.com"
-	case "gitlab":
-		return "gitlab.com"
-	case "bitbucket":
-		return "bitbucket.org"
-	case "git.sr.ht":
-		return "git.sr.ht"
-	default:
-		return site + ".com"
-	}
+// IsLocal reports whether the source is a repository on the local disk
+func (s *Source) IsLocal() bool {
+	return s.Path != ""
+}
+
+// IsArchive reports whether the source is a direct archive URL
+func (s *Source) IsArchive() bool {
+	return s.Site == "url"
 }
 
 // String returns a human-readable representation of the source
 func (s *Source) String() string {
 	result := fmt.Sprintf("%s/%s", s.Owner, s.Repo)
+	if s.IsLocal() {
+		result = s.Path
+	}
+	if s.IsArchive() {
+		return s.URL
+	}
 	if s.Subdir != "" {
 		result += s.Subdir
 	}
@@ -120,31 +135,52 @@ func (s *Source) String() string {
 	return result
 }
 
+// Spec returns a source string without the ref that ParseSource resolves
+// back to the same repository and subdirectory
+func (s *Source) Spec() string {
+	switch {
+	case s.IsArchive():
+		return s.URL
+	case s.IsLocal():
+		return filepath.Join(s.Path, filepath.FromSlash(s.Subdir))
+	default:
+		return s.URL + s.Subdir
+	}
+}
+
 // CacheKey returns a unique key for caching this source
 func (s *Source) CacheKey() string {
+	if s.IsLocal() {
+		return "local" + filepath.ToSlash(s.Path)
+	}
 	return fmt.Sprintf("%s/%s/%s", s.Site, s.Owner, s.Repo)
 }
 
+// Provider returns the provider registered for the source's site.
+// Unknown sites get a plain git provider assuming a "<site>.com" host.
+func (s *Source) Provider() Provider {
+	if s.IsLocal() {
+		return &LocalProvider{}
+	}
+	if s.IsArchive() {
+		return &ArchiveProvider{}
+	}
+	if p, ok := GetProvider(s.Site); ok {
+		return p
+	}
+	return &gitProvider{name: s.Site, baseURL: "https://" + s.Site + ".com"}
+}
+
 // TarballURL returns the URL for downloading the repository tarball
 func (s *Source) TarballURL(hash string) string {
-	switch s.Site {
-	case "github":
-		return fmt.Sprintf("%s/archive/%s.tar.gz", s.URL, hash)
-	case "gitlab":
-		return fmt.Sprintf("%s/-/archive/%s/%s-%s.tar.gz", s.URL, hash, s.Repo, hash)
-	case "bitbucket":
-		return fmt.Sprintf("%s/get/%s.tar.gz", s.URL, hash)
-	case "git.sr.ht":
-		return fmt.Sprintf("%s/archive/%s.tar.gz", s.URL, hash)
-	default:
-		return fmt.Sprintf("%s/archive/%s.tar.gz", s.URL, hash)
-	}
+	return s.Provider().ArchiveURL(s, hash)
 }
 
 // APITarballURL returns the GitHub API URL for downloading private repo tarballs
 func (s *Source) APITarballURL(ref string) string {
-	if s.Site != "github" {
+	p, ok := s.Provider().(*GitHubProvider)
+	if !ok {
 		return ""
 	}
-	return fmt.Sprintf("https://api.github.com/repos/%s/%s/tarball/%s", s.Owner, s.Repo, ref)
+	return p.APIArchiveURL(s, ref)
 }
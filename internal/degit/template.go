@@ -0,0 +1,203 @@
+package degit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/mattn/go-isatty"
+	sdk "github.com/ssgohq/ss-plugin-sdk"
+)
+
+// Variable is a template variable declared in degit.json
+type Variable struct {
+	Name        string `json:"name"`                  // Used as {{name}} and __NAME__
+	Default     string `json:"default,omitempty"`     // Value used when none is given
+	Description string `json:"description,omitempty"` // Shown when prompting
+}
+
+// bracePlaceholder matches {{name}} placeholders, with optional spaces
+var bracePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// ParseVariable parses a "key=value" variable assignment
+func ParseVariable(value string) (string, string, error) {
+	name, val, ok := strings.Cut(value, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("expected key=value, got %q", value)
+	}
+	return name, val, nil
+}
+
+// resolveVariables returns the values of the given variables plus the
+// declared ones, prompting for declared variables that were not given
+func (d *Degit) resolveVariables(declared []Variable) (map[string]string, error) {
+	vars := make(map[string]string, len(d.options.Vars)+len(declared))
+	for name, value := range d.options.Vars {
+		vars[name] = value
+	}
+
+	for _, v := range declared {
+		if v.Name == "" {
+			return nil, fmt.Errorf("variable without a name in degit.json")
+		}
+		if _, ok := vars[v.Name]; ok {
+			continue
+		}
+		value, err := promptVariable(v, d.interactive())
+		if err != nil {
+			return nil, err
+		}
+		vars[v.Name] = value
+	}
+
+	return vars, nil
+}
+
+// promptVariable asks for the value of a variable, falling back to its
+// default in non-interactive mode. A variable without a default is then
+// empty, with a warning.
+func promptVariable(v Variable, interactive bool) (string, error) {
+	if !interactive {
+		if v.Default == "" {
+			sdk.Warning(fmt.Sprintf("No value for variable %s, using an empty value (use --var %s=...)", v.Name, v.Name))
+		}
+		return v.Default, nil
+	}
+
+	label := v.Name
+	if v.Description != "" {
+		label = fmt.Sprintf("%s (%s)", v.Description, v.Name)
+	}
+
+	value, err := promptText(label, v.Default)
+	if err != nil {
+		if err == promptui.ErrInterrupt || err == promptui.ErrEOF {
+			return "", ErrUserCancelled
+		}
+		return "", fmt.Errorf("prompt failed: %w", err)
+	}
+
+	return value, nil
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// placeholderName returns the __NAME__ form of a variable name
+// ("module_path" and "module-path" both become "__MODULE_PATH__")
+func placeholderName(name string) string {
+	upper := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	return "__" + upper + "__"
+}
+
+// renderer substitutes variable placeholders in text
+type renderer struct {
+	vars     map[string]string
+	replacer *strings.Replacer
+}
+
+// newRenderer creates a renderer for the given variables
+func newRenderer(vars map[string]string) *renderer {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		pairs = append(pairs, placeholderName(name), vars[name])
+	}
+
+	return &renderer{vars: vars, replacer: strings.NewReplacer(pairs...)}
+}
+
+// render substitutes {{name}} and __NAME__ placeholders of known variables;
+// unknown placeholders are left untouched
+func (r *renderer) render(text string) string {
+	text = bracePlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+		name := bracePlaceholder.FindStringSubmatch(match)[1]
+		if value, ok := r.vars[name]; ok {
+			return value
+		}
+		return match
+	})
+	return r.replacer.Replace(text)
+}
+
+// renderTemplate substitutes variables in the contents of the text files
+// under dir and in file and directory names. Binary files (images,
+// archives, ...) are left untouched.
+func renderTemplate(dir string, vars map[string]string) error {
+	r := newRenderer(vars)
+
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		paths = append(paths, path)
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return renderFile(path, info.Mode().Perm(), r)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Rename children before their parents so collected paths stay valid
+	for i := len(paths) - 1; i >= 0; i-- {
+		path := paths[i]
+		name := filepath.Base(path)
+		rendered := r.render(name)
+		if rendered == name {
+			continue
+		}
+		if rendered == "" || rendered == "." || rendered == ".." || strings.ContainsAny(rendered, `/\`) {
+			return fmt.Errorf("invalid file name after substitution: %q", rendered)
+		}
+		target := filepath.Join(filepath.Dir(path), rendered)
+		if err := os.Rename(path, target); err != nil {
+			return fmt.Errorf("failed to rename %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// renderFile substitutes variables in a text file
+func renderFile(path string, mode os.FileMode, r *renderer) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if isBinary(data) {
+		return nil
+	}
+
+	rendered := r.render(string(data))
+	if rendered == string(data) {
+		return nil
+	}
+	return os.WriteFile(path, []byte(rendered), mode)
+}
@@ -0,0 +1,120 @@
+package degit
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveVariables(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    map[string]string
+		declared []Variable
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "given values win over defaults",
+			given:    map[string]string{"name": "app"},
+			declared: []Variable{{Name: "name", Default: "default"}},
+			want:     map[string]string{"name": "app"},
+		},
+		{
+			name:     "default when not interactive",
+			declared: []Variable{{Name: "name", Default: "default"}},
+			want:     map[string]string{"name": "default"},
+		},
+		{
+			name:     "empty without a default",
+			declared: []Variable{{Name: "name"}},
+			want:     map[string]string{"name": ""},
+		},
+		{
+			name:  "undeclared given values are kept",
+			given: map[string]string{"extra": "x"},
+			want:  map[string]string{"extra": "x"},
+		},
+		{
+			name:     "variable without a name",
+			declared: []Variable{{Default: "x"}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(Options{Vars: tt.given, NonInteractive: true})
+			got, err := d.resolveVariables(tt.declared)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveVariables() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !maps.Equal(got, tt.want) {
+				t.Errorf("resolveVariables() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	vars := map[string]string{"name": "app", "module_path": "example.com/app"}
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "contents",
+			files: map[string]string{"go.mod": "module {{module_path}}\n", "main.go": "// {{ name }} in __MODULE_PATH__\n"},
+			want:  map[string]string{"go.mod": "module example.com/app\n", "main.go": "// app in example.com/app\n"},
+		},
+		{
+			name:  "unknown placeholders are kept",
+			files: map[string]string{"a.txt": "{{other}} __OTHER__ {{name}}"},
+			want:  map[string]string{"a.txt": "{{other}} __OTHER__ app"},
+		},
+		{
+			name:  "binary files are skipped",
+			files: map[string]string{"logo.png": "\x89PNG\x00{{name}}", "{{name}}.bin": "\x00__NAME__"},
+			want:  map[string]string{"logo.png": "\x89PNG\x00{{name}}", "app.bin": "\x00__NAME__"},
+		},
+		{
+			name: "nested directories are renamed child first",
+			files: map[string]string{
+				"__NAME__/__NAME__/{{name}}.go": "package {{name}}\n",
+				"__NAME__/cmd/main.go":          "package main\n",
+				"__NAME__.txt":                  "{{name}}",
+			},
+			want: map[string]string{
+				"app/app/app.go":  "package app\n",
+				"app/cmd/main.go": "package main\n",
+				"app.txt":         "app",
+			},
+		},
+		{
+			name:    "names cannot contain separators",
+			files:   map[string]string{"__MODULE_PATH__.txt": "x"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "template")
+			writeTestTree(t, dir, tt.files)
+
+			err := renderTemplate(dir, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := readTestTree(t, dir); !maps.Equal(got, tt.want) {
+				t.Errorf("renderTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
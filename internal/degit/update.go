@@ -0,0 +1,304 @@
+package degit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Update statuses for files
+const (
+	UpdateAdded    = "added"    // New in the template
+	UpdateUpdated  = "updated"  // Unchanged locally and replaced by the new version
+	UpdateRemoved  = "removed"  // Removed from the template and unchanged locally
+	UpdateMerged   = "merged"   // Changed on both sides and merged cleanly
+	UpdateConflict = "conflict" // Merged with conflict markers
+	UpdateRejected = "rejected" // Could not be merged; new version written to *.rej
+	UpdateKept     = "kept"     // Removed from the template but modified locally
+)
+
+// rejectSuffix is appended to files holding template changes that could not
+// be merged
+const rejectSuffix = ".rej"
+
+// UpdateEntry describes what an update did to a single file
+type UpdateEntry struct {
+	Path   string // Path relative to the project
+	Status string // One of the Update* constants
+}
+
+// UpdateResult describes the outcome of an update
+type UpdateResult struct {
+	From    string        // Previously recorded commit hash
+	To      string        // New commit hash
+	Entries []UpdateEntry // Changed files, sorted by path
+}
+
+// Count returns the number of entries with the given status
+func (r *UpdateResult) Count(status string) int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Update pulls template changes into a project that was cloned with a lock
+// file. The template is materialized at the recorded hash (the base) and at
+// ref (the recorded ref if empty), and the differences between them are
+// merged three-way into dest: files the project did not touch are replaced,
+// files changed on both sides are merged line by line, with conflict
+// markers where the changes overlap, and changes that cannot be merged are
+// written next to the file as *.rej.
+//
+// The new files and lock are written to a staging directory next to dest and
+// only moved into dest once every file has been updated, so a failure leaves
+// the project and its lock as they were.
+func (d *Degit) Update(dest string, ref string) (*UpdateResult, error) {
+	lock, err := ReadLock(dest)
+	if err != nil {
+		return nil, err
+	}
+	if lock.Hash == "" {
+		return nil, fmt.Errorf("no commit hash recorded in %s", LockFileName)
+	}
+	if ref == "" {
+		ref = lock.Ref
+	}
+
+	src, err := lock.ParseSource(ref)
+	if err != nil {
+		return nil, err
+	}
+	if src.IsArchive() {
+		return nil, fmt.Errorf("update is not supported for archive URLs")
+	}
+
+	tempDir, err := os.MkdirTemp("", "degit-update-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	// Materialize both template versions with the original extraction options
+	template := d.forLock(lock)
+
+	baseSrc := *src
+	baseSrc.Ref = lock.Hash
+	baseDir := filepath.Join(tempDir, "base")
+	if _, err := template.cloneInto(&baseSrc, baseDir); err != nil {
+		return nil, fmt.Errorf("failed to fetch template at %s: %w", lock.Hash, err)
+	}
+
+	theirsDir := filepath.Join(tempDir, "theirs")
+	hash, err := template.cloneInto(src, theirsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch template at %s: %w", src.Ref, err)
+	}
+
+	result := &UpdateResult{From: lock.Hash, To: hash}
+	if hash == lock.Hash {
+		return result, nil
+	}
+
+	paths, err := templateFiles(baseDir, theirsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	theirsLabel := "template"
+	if len(hash) >= 8 {
+		theirsLabel += " (" + hash[:8] + ")"
+	}
+
+	staging, err := newStagingDir(dest)
+	if err != nil {
+		return nil, err
+	}
+	// No-op once the staging directory has been committed
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	var removed []string
+	for _, rel := range paths {
+		status, err := updateFile(rel, baseDir, theirsDir, dest, staging, theirsLabel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", rel, err)
+		}
+		if status == UpdateRemoved {
+			removed = append(removed, rel)
+		}
+		if status != "" {
+			result.Entries = append(result.Entries, UpdateEntry{Path: rel, Status: status})
+		}
+	}
+
+	// Point the lock at the new version
+	files, err := checksumFiles(theirsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum files: %w", err)
+	}
+	lock.Ref = src.Ref
+	lock.Hash = hash
+	lock.Files = files
+	if d.options.Version != "" {
+		lock.Version = d.options.Version
+	}
+	if err := WriteLock(staging, lock); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", LockFileName, err)
+	}
+
+	if err := commitStaging(staging, dest); err != nil {
+		return nil, fmt.Errorf("failed to move files into %s: %w", dest, err)
+	}
+	for _, rel := range removed {
+		if err := os.Remove(filepath.Join(dest, filepath.FromSlash(rel))); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove %s: %w", rel, err)
+		}
+	}
+
+	return result, nil
+}
+
+// forLock returns a Degit that extracts templates with the options recorded
+// in lock
+func (d *Degit) forLock(lock *Lock) *Degit {
+	return New(Options{
+		Cache:          d.options.Cache,
+		Verbose:        d.options.Verbose,
+		Token:          d.options.Token,
+		CacheZstd:      d.options.CacheZstd,
+		Include:        lock.Include,
+		Exclude:        lock.Exclude,
+		Mappings:       lock.Mappings,
+		Vars:           lock.Variables,
+		NonInteractive: d.options.NonInteractive,
+	})
+}
+
+// templateFiles returns the sorted relative paths of the regular files in
+// any of the given directories
+func templateFiles(dirs ...string) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			if rel != LockFileName {
+				seen[filepath.ToSlash(rel)] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for rel := range seen {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// updateFile works out the template change of a single file to dest and
+// returns its status, or "" if nothing had to be done. New contents are
+// written to the same path in staging; files to remove (UpdateRemoved) are
+// left to the caller.
+func updateFile(rel string, baseDir string, theirsDir string, dest string, staging string, theirsLabel string) (string, error) {
+	name := filepath.FromSlash(rel)
+	destPath := filepath.Join(dest, name)
+	stagedPath := filepath.Join(staging, name)
+	theirsPath := filepath.Join(theirsDir, name)
+
+	base, hasBase, err := readOptional(filepath.Join(baseDir, name))
+	if err != nil {
+		return "", err
+	}
+	theirs, hasTheirs, err := readOptional(theirsPath)
+	if err != nil {
+		return "", err
+	}
+	local, hasLocal, err := readOptional(destPath)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case hasBase && hasTheirs && bytes.Equal(base, theirs):
+		// Unchanged in the template
+		return "", nil
+
+	case hasTheirs && hasLocal && bytes.Equal(local, theirs):
+		// Already up to date
+		return "", nil
+
+	case !hasTheirs:
+		if !hasLocal {
+			return "", nil
+		}
+		if hasBase && bytes.Equal(local, base) {
+			return UpdateRemoved, nil
+		}
+		return UpdateKept, nil
+
+	case !hasLocal:
+		if !hasBase {
+			return UpdateAdded, copyFile(theirsPath, stagedPath, fileMode(theirsPath))
+		}
+		// Deleted locally but changed in the template
+		return UpdateRejected, copyFile(theirsPath, stagedPath+rejectSuffix, fileMode(theirsPath))
+
+	case hasBase && bytes.Equal(local, base):
+		return UpdateUpdated, copyFile(theirsPath, stagedPath, fileMode(theirsPath))
+
+	case isBinary(base) || isBinary(local) || isBinary(theirs):
+		return UpdateRejected, copyFile(theirsPath, stagedPath+rejectSuffix, fileMode(theirsPath))
+	}
+
+	merged, conflict := merge3(base, local, theirs, "local", theirsLabel)
+	if err := os.MkdirAll(filepath.Dir(stagedPath), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(stagedPath, merged, fileMode(destPath)); err != nil {
+		return "", err
+	}
+	if conflict {
+		return UpdateConflict, nil
+	}
+	return UpdateMerged, nil
+}
+
+// readOptional reads a file, reporting whether it exists
+func readOptional(path string) ([]byte, bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// fileMode returns the permission bits of path, or 0644 if it cannot be
+// read
+func fileMode(path string) os.FileMode {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0644
+	}
+	return info.Mode().Perm()
+}
@@ -0,0 +1,156 @@
+package degit
+
+import (
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		local   map[string]string // Local changes made after the clone ("" removes a file)
+		next    map[string]string // Template changes of the new commit ("" removes a file)
+		want    map[string]string // Project files after the update
+		status  map[string]string // Update status by path
+		wantErr bool
+	}{
+		{
+			name:   "untouched files follow the template",
+			next:   map[string]string{"a.txt": "a2\n", "b.txt": "", "c.txt": "c\n"},
+			want:   map[string]string{"a.txt": "a2\n", "c.txt": "c\n"},
+			status: map[string]string{"a.txt": UpdateUpdated, "b.txt": UpdateRemoved, "c.txt": UpdateAdded},
+		},
+		{
+			name:   "local changes are merged or kept",
+			local:  map[string]string{"a.txt": "a\nlocal\n", "b.txt": "b local\n"},
+			next:   map[string]string{"a.txt": "template\na\n", "b.txt": ""},
+			want:   map[string]string{"a.txt": "template\na\nlocal\n", "b.txt": "b local\n"},
+			status: map[string]string{"a.txt": UpdateMerged, "b.txt": UpdateKept},
+		},
+		{
+			name:    "failure leaves the project untouched",
+			local:   map[string]string{"b.txt": ""},
+			next:    map[string]string{"a.txt": "a2\n", "b.txt": "b2\n"},
+			want:    map[string]string{"a.txt": "a\n", "b.txt/x": "blocks b.txt"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			repo := newTestRepo(t, map[string]string{"a.txt": "a\n", "b.txt": "b\n"})
+			dest := filepath.Join(t.TempDir(), "project")
+			cloneTestRepo(t, repo, dest, Options{Lock: true})
+			before, err := ReadLock(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			editTestTree(t, dest, tt.local)
+			if tt.wantErr {
+				// A directory where the template has a file cannot be updated
+				writeTestFile(t, filepath.Join(dest, "b.txt", "x"), "blocks b.txt")
+			}
+			next := commitTestFiles(t, repo, tt.next)
+
+			result, err := New(Options{NonInteractive: true}).Update(dest, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := readTestTree(t, dest)
+			delete(got, LockFileName)
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("project = %v, want %v", got, tt.want)
+			}
+
+			lock, err := ReadLock(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if lock.Hash != before.Hash {
+					t.Errorf("lock hash = %s after a failed update, want %s", lock.Hash, before.Hash)
+				}
+				return
+			}
+			if lock.Hash != next || result.To != next {
+				t.Errorf("lock hash = %s, result.To = %s, want %s", lock.Hash, result.To, next)
+			}
+			status := make(map[string]string)
+			for _, e := range result.Entries {
+				status[e.Path] = e.Status
+			}
+			if !maps.Equal(status, tt.status) {
+				t.Errorf("statuses = %v, want %v", status, tt.status)
+			}
+		})
+	}
+}
+
+// newTestRepo creates a git repository with files committed on its main
+// branch and returns its path
+func newTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	commitTestFiles(t, repo, files)
+	return repo
+}
+
+// commitTestFiles writes files into repo ("" removes a file), commits them
+// and returns the commit hash
+func commitTestFiles(t *testing.T, repo string, files map[string]string) string {
+	t.Helper()
+	editTestTree(t, repo, files)
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "test")
+	return runGit(t, repo, "rev-parse", "HEAD")
+}
+
+// editTestTree writes files below dir, removing those whose content is ""
+func editTestTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if content == "" {
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		writeTestFile(t, path, content)
+	}
+}
+
+// runGit runs git in dir and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// cloneTestRepo clones a local repository (or a ./path, file:// URL or
+// #ref spec of one) into dest
+func cloneTestRepo(t *testing.T, spec string, dest string, opts Options) {
+	t.Helper()
+	src, err := ParseSource(spec)
+	if err != nil {
+		t.Fatalf("ParseSource(%q) error = %v", spec, err)
+	}
+	opts.NonInteractive = true
+	if err := New(opts).Clone(src, dest); err != nil {
+		t.Fatalf("Clone(%q) error = %v", spec, err)
+	}
+}
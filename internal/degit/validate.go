@@ -0,0 +1,509 @@
+package degit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem found in a manifest
+type ValidationError struct {
+	File    string // Manifest file name
+	Line    int    // 1-based line (0 if unknown)
+	Column  int    // 1-based column (0 if unknown)
+	Path    string // JSON path of the offending value, e.g. $.actions[0].files
+	Message string
+	Warning bool // The manifest still loads (unknown fields are ignored)
+}
+
+// Error formats the error as file:line:column: path: message
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.Path, e.Message)
+}
+
+// Manifest node kinds
+const (
+	nodeScalar = iota
+	nodeObject
+	nodeArray
+)
+
+// manifestNode is a parsed manifest value with its position
+type manifestNode struct {
+	kind   int
+	value  interface{}              // Scalar value: string, float64, bool or nil
+	keys   []string                 // Object keys in order
+	fields map[string]*manifestNode // Object values by key
+	keyPos map[string][2]int        // Line and column of object keys
+	items  []*manifestNode          // Array items
+	line   int
+	column int
+}
+
+// set adds an object field
+func (n *manifestNode) set(key string, value *manifestNode, line int, column int) {
+	if n.fields == nil {
+		n.fields = make(map[string]*manifestNode)
+		n.keyPos = make(map[string][2]int)
+	}
+	if _, ok := n.fields[key]; !ok {
+		n.keys = append(n.keys, key)
+	}
+	n.fields[key] = value
+	n.keyPos[key] = [2]int{line, column}
+}
+
+// typeName returns the JSON type of the node
+func (n *manifestNode) typeName() string {
+	switch n.kind {
+	case nodeObject:
+		return "object"
+	case nodeArray:
+		return "array"
+	}
+	switch v := n.value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "null"
+}
+
+// FindManifest returns the path of the manifest in dir
+func FindManifest(dir string) (string, error) {
+	for _, name := range ManifestNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no %s found in %s", strings.Join(ManifestNames, ", "), dir)
+}
+
+// ValidateFile validates the manifest file at path
+func ValidateFile(path string) ([]ValidationError, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateManifest(filepath.Base(path), data), nil
+}
+
+// ValidateManifest checks the contents of the manifest file with the given
+// name against the manifest schema and the requirements of each action
+func ValidateManifest(name string, data []byte) []ValidationError {
+	v := &validator{file: name}
+
+	var root *manifestNode
+	var err error
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml":
+		root, err = parseYAMLNode(data)
+	default:
+		stripped := stripJSONComments(data)
+		if trimmed := bytes.TrimSpace(stripped); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			return nil // Empty manifest, as accepted by ParseManifest
+		}
+		root, err = parseJSONNode(stripped)
+	}
+	if err != nil {
+		v.syntaxError(data, err)
+		return v.errors
+	}
+	if root == nil {
+		return nil // Empty YAML document
+	}
+
+	var schema map[string]interface{}
+	schemaData, _ := Schema()
+	_ = json.Unmarshal(schemaData, &schema)
+	v.defs, _ = schema["$defs"].(map[string]interface{})
+
+	v.check(root, schema, "$")
+
+	actions := root
+	actionsPath := "$"
+	if root.kind == nodeObject {
+		actions = root.fields["actions"]
+		actionsPath = "$.actions"
+	}
+	if actions != nil && actions.kind == nodeArray {
+		for i, action := range actions.items {
+			v.checkAction(action, fmt.Sprintf("%s[%d]", actionsPath, i))
+		}
+	}
+
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		a, b := v.errors[i], v.errors[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	return v.errors
+}
+
+// validator collects the problems of a manifest
+type validator struct {
+	file   string
+	defs   map[string]interface{}
+	errors []ValidationError
+}
+
+// errorf records a problem at the given position
+func (v *validator) errorf(line int, column int, path string, format string, args ...interface{}) {
+	v.errors = append(v.errors, ValidationError{
+		File:    v.file,
+		Line:    line,
+		Column:  column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// syntaxError records a parse error, locating it when possible
+func (v *validator) syntaxError(data []byte, err error) {
+	line, column := 0, 0
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, column = position(data, int(syntaxErr.Offset))
+	case errors.As(err, &typeErr):
+		line, column = position(data, int(typeErr.Offset))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		line, column = position(data, len(data))
+		err = fmt.Errorf("unexpected end of file")
+	default:
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			column = 1
+		}
+	}
+
+	v.errorf(line, column, "$", "%v", err)
+}
+
+// yamlErrorLine finds the line number in yaml.v3 error messages
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// check validates a node against a (subset of) JSON Schema
+func (v *validator) check(n *manifestNode, schema map[string]interface{}, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema, _ = v.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+	}
+
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		var types []string
+		for _, option := range oneOf {
+			sub, _ := option.(map[string]interface{})
+			if ref, ok := sub["$ref"].(string); ok {
+				sub, _ = v.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+			}
+			want, _ := sub["type"].(string)
+			if typeMatches(n, want) {
+				v.check(n, sub, path)
+				return
+			}
+			types = append(types, want)
+		}
+		v.errorf(n.line, n.column, path, "expected %s, got %s", strings.Join(types, " or "), n.typeName())
+		return
+	}
+
+	if want, ok := schema["type"].(string); ok && !typeMatches(n, want) {
+		v.errorf(n.line, n.column, path, "expected %s, got %s", want, n.typeName())
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		valid := false
+		names := make([]string, len(enum))
+		for i, value := range enum {
+			names[i] = fmt.Sprint(value)
+			valid = valid || value == n.value
+		}
+		if !valid {
+			v.errorf(n.line, n.column, path, "invalid value %q (expected one of: %s)", fmt.Sprint(n.value), strings.Join(names, ", "))
+		}
+	}
+
+	if number, ok := n.value.(float64); ok {
+		if minimum, ok := schema["minimum"].(float64); ok && number < minimum {
+			v.errorf(n.line, n.column, path, "must be at least %v", minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && number > maximum {
+			v.errorf(n.line, n.column, path, "must be at most %v", maximum)
+		}
+	}
+
+	switch n.kind {
+	case nodeArray:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range n.items {
+				v.check(item, items, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+
+	case nodeObject:
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for _, key := range n.keys {
+			child := n.fields[key]
+			childPath := path + "." + key
+			if prop, ok := properties[key].(map[string]interface{}); ok {
+				v.check(child, prop, childPath)
+			} else if additional != nil {
+				v.check(child, additional, childPath)
+			} else if schema["additionalProperties"] == false {
+				pos := n.keyPos[key]
+				v.errorf(pos[0], pos[1], childPath, "unknown field %q%s", key, suggest(key, properties))
+				v.errors[len(v.errors)-1].Warning = true
+			}
+		}
+
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := n.fields[name.(string)]; !ok {
+				v.errorf(n.line, n.column, path, "missing required field %q", name)
+			}
+		}
+	}
+}
+
+// checkAction checks the requirements of an action beyond the schema
+func (v *validator) checkAction(n *manifestNode, path string) {
+	if n.kind != nodeObject {
+		return
+	}
+	name, _ := fieldValue(n, "action").(string)
+
+	for _, field := range actionRequired[name] {
+		if _, ok := n.fields[field]; !ok {
+			v.errorf(n.line, n.column, path, "%s action requires %q", name, field)
+		}
+	}
+
+	if name == "prompt" {
+		promptType, _ := fieldValue(n, "type").(string)
+		if promptType == PromptSelect || promptType == PromptMultiSelect {
+			if choices := n.fields["choices"]; choices == nil || len(choices.items) == 0 {
+				v.errorf(n.line, n.column, path, "%s prompt requires \"choices\"", promptType)
+			}
+		}
+	}
+
+	if expr, ok := fieldValue(n, "if").(string); ok {
+		if _, err := evalCondition(expr, nil); err != nil {
+			f := n.fields["if"]
+			v.errorf(f.line, f.column, path+".if", "%v", err)
+		}
+	}
+
+	if search, ok := fieldValue(n, "search").(string); ok && fieldValue(n, "regex") == true && !bracePlaceholder.MatchString(search) {
+		if _, err := regexp.Compile(search); err != nil {
+			f := n.fields["search"]
+			v.errorf(f.line, f.column, path+".search", "invalid regex: %v", err)
+		}
+	}
+}
+
+// fieldValue returns the scalar value of an object field, or nil
+func fieldValue(n *manifestNode, key string) interface{} {
+	if f, ok := n.fields[key]; ok && f.kind == nodeScalar {
+		return f.value
+	}
+	return nil
+}
+
+// typeMatches reports whether a node has the given JSON Schema type (an
+// empty type matches anything)
+func typeMatches(n *manifestNode, want string) bool {
+	got := n.typeName()
+	return want == "" || want == got || want == "number" && got == "integer"
+}
+
+// suggest returns a "did you mean" hint for an unknown field
+func suggest(key string, properties map[string]interface{}) string {
+	best, bestDistance := "", 3
+	for name := range properties {
+		if d := editDistance(key, name); d < bestDistance || d == bestDistance && name < best {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// position returns the 1-based line and column of a byte offset
+func position(data []byte, offset int) (int, int) {
+	offset = min(offset, len(data))
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+	return line, column
+}
+
+// parseJSONNode parses JSON into nodes carrying their positions
+func parseJSONNode(data []byte) (*manifestNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	root, err := decodeJSONNode(dec, data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		line, column := position(data, skipJSONSpace(data, int(dec.InputOffset())))
+		return nil, fmt.Errorf("unexpected data after the manifest at line %d, column %d", line, column)
+	}
+	return root, nil
+}
+
+// decodeJSONNode decodes the next JSON value
+func decodeJSONNode(dec *json.Decoder, data []byte) (*manifestNode, error) {
+	n := &manifestNode{}
+	n.line, n.column = position(data, skipJSONSpace(data, int(dec.InputOffset())))
+
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		n.kind = nodeObject
+		for dec.More() {
+			line, column := position(data, skipJSONSpace(data, int(dec.InputOffset())))
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONNode(dec, data)
+			if err != nil {
+				return nil, err
+			}
+			n.set(key.(string), value, line, column)
+		}
+		_, err = dec.Token()
+
+	case json.Delim('['):
+		n.kind = nodeArray
+		for dec.More() {
+			item, err := decodeJSONNode(dec, data)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+		}
+		_, err = dec.Token()
+
+	default:
+		n.value = token
+	}
+
+	return n, err
+}
+
+// skipJSONSpace returns the offset of the next token at or after offset,
+// skipping whitespace and separators
+func skipJSONSpace(data []byte, offset int) int {
+	for offset < len(data) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// parseYAMLNode parses YAML into nodes carrying their positions
+func parseYAMLNode(data []byte) (*manifestNode, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return convertYAMLNode(doc.Content[0])
+}
+
+// convertYAMLNode converts a yaml.v3 node
+func convertYAMLNode(y *yaml.Node) (*manifestNode, error) {
+	if y.Kind == yaml.AliasNode {
+		y = y.Alias
+	}
+	n := &manifestNode{line: y.Line, column: y.Column}
+
+	switch y.Kind {
+	case yaml.MappingNode:
+		n.kind = nodeObject
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			key := y.Content[i]
+			value, err := convertYAMLNode(y.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			n.set(key.Value, value, key.Line, key.Column)
+		}
+
+	case yaml.SequenceNode:
+		n.kind = nodeArray
+		for _, item := range y.Content {
+			value, err := convertYAMLNode(item)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, value)
+		}
+
+	default:
+		var value interface{}
+		if err := y.Decode(&value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", y.Line, err)
+		}
+		switch v := value.(type) {
+		case int:
+			value = float64(v)
+		case int64:
+			value = float64(v)
+		case uint64:
+			value = float64(v)
+		}
+		n.value = value
+	}
+
+	return n, nil
+}
@@ -0,0 +1,85 @@
+package degit
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValidateManifest(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		want []ValidationError
+	}{
+		{
+			name: "valid JSON",
+			file: "degit.json",
+			data: "[{\"action\": \"remove\", \"files\": [\"a\"]}]",
+		},
+		{
+			name: "JSON with comments and trailing commas",
+			file: "degit.jsonc",
+			data: "// setup\n[{\"action\": \"run\", \"command\": \"make\",}, ]\n",
+		},
+		{
+			name: "empty manifest",
+			file: "degit.json",
+			data: "null",
+		},
+		{
+			name: "JSON actions",
+			file: "degit.json",
+			data: "[\n  {\"action\": \"remove\", \"fiels\": [\"a\"]},\n  {\"action\": \"clon\"}\n]\n",
+			want: []ValidationError{
+				{File: "degit.json", Line: 2, Column: 3, Path: "$[0]", Message: `remove action requires "files"`},
+				{File: "degit.json", Line: 2, Column: 24, Path: "$[0].fiels", Message: `unknown field "fiels" (did you mean "files"?)`, Warning: true},
+				{File: "degit.json", Line: 3, Column: 14, Path: "$[1].action", Message: `invalid value "clon" (expected one of: clone, remove, rename, move, copy, replace, patch, prompt, run)`},
+			},
+		},
+		{
+			name: "JSON types",
+			file: "degit.json",
+			data: "{\n  \"variables\": [{\"name\": 1}],\n  \"actions\": {}\n}\n",
+			want: []ValidationError{
+				{File: "degit.json", Line: 2, Column: 26, Path: "$.variables[0].name", Message: "expected string, got integer"},
+				{File: "degit.json", Line: 3, Column: 14, Path: "$.actions", Message: "expected array, got object"},
+			},
+		},
+		{
+			name: "JSON syntax error",
+			file: "degit.json",
+			data: "[\n  {\"action\": \"remove\",\n",
+			want: []ValidationError{
+				{File: "degit.json", Line: 3, Column: 1, Path: "$", Message: "unexpected end of JSON input"},
+			},
+		},
+		{
+			name: "YAML actions",
+			file: "degit.yaml",
+			data: "actions:\n  - action: remove\n    fiels: [a]\n  - action: clon\n",
+			want: []ValidationError{
+				{File: "degit.yaml", Line: 2, Column: 5, Path: "$.actions[0]", Message: `remove action requires "files"`},
+				{File: "degit.yaml", Line: 3, Column: 5, Path: "$.actions[0].fiels", Message: `unknown field "fiels" (did you mean "files"?)`, Warning: true},
+				{File: "degit.yaml", Line: 4, Column: 13, Path: "$.actions[1].action", Message: `invalid value "clon" (expected one of: clone, remove, rename, move, copy, replace, patch, prompt, run)`},
+			},
+		},
+		{
+			name: "YAML syntax error",
+			file: "degit.yaml",
+			data: "actions:\n  - action: remove\n    files: [a\n",
+			want: []ValidationError{
+				{File: "degit.yaml", Line: 2, Column: 1, Path: "$", Message: "yaml: line 2: did not find expected ',' or ']'"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateManifest(tt.file, []byte(tt.data))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateManifest() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
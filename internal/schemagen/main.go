@@ -0,0 +1,31 @@
+// Command schemagen writes the JSON Schema of degit manifests
+// (degit.schema.json), generated from the manifest types.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ssgohq/ss-plugin-degit/internal/degit"
+)
+
+func main() {
+	output := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	data, err := degit.Schema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write schema: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -5,9 +5,12 @@
 package main
 
 import (
+	"encoding/csv"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	sdk "github.com/ssgohq/ss-plugin-sdk"
 
@@ -24,12 +27,28 @@ var (
 
 // DegitPlugin implements the sdk.Plugin interface
 type DegitPlugin struct {
-	source  string
-	dest    string
-	force   bool
-	cache   bool
-	mode    string
-	verbose bool
+	command  string // Subcommand ("update", "diff" or "validate"), empty for clone
+	source   string
+	dest     string
+	force    bool
+	cache    bool
+	mode     string
+	verbose  bool
+	zstd     bool
+	include  []string
+	exclude  []string
+	maps     []string
+	dryRun   bool
+	conflict string
+	ref      string
+	noLock   bool
+	lockFmt  string
+	vars     []string
+	answers  string
+	noInput  bool
+	scripts  bool
+	graph    bool
+	maxDepth int
 }
 
 // Metadata returns plugin information
@@ -42,7 +61,7 @@ func (p *DegitPlugin) Metadata() sdk.Metadata {
 			{
 				Name:        "degit",
 				Description: "Clone a git repository without history",
-				Usage:       "ss degit <source> [dest] [flags]",
+				Usage:       "ss degit <source> [dest] [flags] | ss degit update|diff [dest] [flags] | ss degit validate [dir]",
 			},
 		},
 	}
@@ -55,6 +74,51 @@ func (p *DegitPlugin) Init(ctx *sdk.Context) error {
 	p.cache = ctx.Flags["offline"] == "true"
 	p.mode = ctx.Flags["mode"]
 	p.verbose = ctx.Flags["verbose"] == "true"
+	p.zstd = ctx.Flags["cache-zstd"] == "true"
+	p.dryRun = ctx.Flags["dry-run"] == "true"
+	p.conflict = ctx.Flags["conflict"]
+	p.ref = ctx.Flags["ref"]
+	p.noLock = ctx.Flags["no-lock"] == "true"
+	p.lockFmt = ctx.Flags["lock-format"]
+	p.answers = ctx.Flags["answers"]
+	p.noInput = ctx.Flags["non-interactive"] == "true"
+	p.scripts = ctx.Flags["allow-scripts"] == "true"
+	p.graph = ctx.Flags["show-graph"] == "true"
+
+	var err error
+	if p.include, err = splitList("include", ctx.Flags["include"]); err != nil {
+		return err
+	}
+	if p.exclude, err = splitList("exclude", ctx.Flags["exclude"]); err != nil {
+		return err
+	}
+	if p.maps, err = splitList("map", ctx.Flags["map"]); err != nil {
+		return err
+	}
+	if p.vars, err = splitList("var", ctx.Flags["var"]); err != nil {
+		return err
+	}
+
+	if value := ctx.Flags["max-depth"]; value != "" {
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth < 1 {
+			return fmt.Errorf("invalid --max-depth %q (expected a positive number)", value)
+		}
+		p.maxDepth = depth
+	}
+
+	if p.lockFmt == "" {
+		p.lockFmt = degit.LockJSON
+	}
+	if err := degit.ValidateLockFormat(p.lockFmt); err != nil {
+		return err
+	}
+
+	if p.conflict != "" {
+		if err := degit.ValidateConflictPolicy(p.conflict); err != nil {
+			return err
+		}
+	}
 
 	// Default mode to tar
 	if p.mode == "" {
@@ -62,18 +126,63 @@ func (p *DegitPlugin) Init(ctx *sdk.Context) error {
 	}
 
 	// Parse positional arguments
-	if len(ctx.Args) > 0 {
-		p.source = ctx.Args[0]
+	args := ctx.Args
+	if len(args) > 0 && (args[0] == "update" || args[0] == "diff" || args[0] == "validate") {
+		p.command = args[0]
+		args = args[1:]
 	}
-	if len(ctx.Args) > 1 {
-		p.dest = ctx.Args[1]
+
+	switch p.command {
+	case "update", "diff", "validate":
+		if len(args) > 0 {
+			p.dest = args[0]
+		}
+	default:
+		if len(args) > 0 {
+			p.source = args[0]
+		}
+		if len(args) > 1 {
+			p.dest = args[1]
+		}
 	}
 
 	return nil
 }
 
+// splitList parses the value of a repeatable flag. The SDK passes repeated
+// values the way pflag prints a string array: a single CSV record in
+// brackets, with values holding commas or quotes quoted ("[a,\"b,c\"]").
+// A value without brackets is a single item.
+func splitList(name string, value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return []string{value}, nil
+	}
+
+	list := value[1 : len(value)-1]
+	if list == "" {
+		return nil, nil
+	}
+	items, err := csv.NewReader(strings.NewReader(list)).Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s value %s: %w", name, value, err)
+	}
+	return items, nil
+}
+
 // Execute runs the plugin's main logic
 func (p *DegitPlugin) Execute(ctx *sdk.Context) error {
+	switch p.command {
+	case "update":
+		return p.runUpdate(ctx)
+	case "diff":
+		return p.runDiff(ctx)
+	case "validate":
+		return p.runValidate(ctx)
+	}
+
 	// If no source provided, run interactive mode
 	if p.source == "" {
 		return p.runInteractive(ctx)
@@ -96,18 +205,67 @@ func (p *DegitPlugin) Execute(ctx *sdk.Context) error {
 		dest = filepath.Join(ctx.WorkingDir, dest)
 	}
 
+	// Parse subdirectory mappings
+	var mappings []degit.Mapping
+	for _, value := range p.maps {
+		m, err := degit.ParseMapping(value)
+		if err != nil {
+			return fmt.Errorf("invalid mapping: %w", err)
+		}
+		mappings = append(mappings, m)
+	}
+
+	// Parse template variables; --var takes precedence over the answers file
+	vars := make(map[string]string, len(p.vars))
+	if p.answers != "" {
+		answers, err := degit.LoadAnswers(p.answers)
+		if err != nil {
+			return fmt.Errorf("invalid answers file: %w", err)
+		}
+		vars = answers
+	}
+	for _, value := range p.vars {
+		name, val, err := degit.ParseVariable(value)
+		if err != nil {
+			return fmt.Errorf("invalid variable: %w", err)
+		}
+		vars[name] = val
+	}
+
 	// Get GitHub token for private repos
 	token := auth.GitHubToken()
 
 	// Create degit instance
 	d := degit.New(degit.Options{
-		Force:   p.force,
-		Cache:   p.cache,
-		Mode:    p.mode,
-		Verbose: p.verbose,
-		Token:   token,
+		Force:          p.force,
+		Cache:          p.cache,
+		Mode:           p.mode,
+		Verbose:        p.verbose,
+		Token:          token,
+		CacheZstd:      p.zstd,
+		Include:        p.include,
+		Exclude:        p.exclude,
+		Mappings:       mappings,
+		Conflict:       p.conflict,
+		Lock:           !p.noLock,
+		LockFormat:     p.lockFmt,
+		Version:        version,
+		Vars:           vars,
+		NonInteractive: p.noInput,
+		AllowScripts:   p.scripts,
+		MaxDepth:       p.maxDepth,
 	})
 
+	// Show what would happen without touching the destination
+	if p.dryRun {
+		plan, err := d.Plan(src, dest)
+		if err != nil {
+			return err
+		}
+		printPlan(plan)
+		return nil
+	}
+
 	// Clone the repository
 	if p.verbose {
 		sdk.Info(fmt.Sprintf("Cloning %s to %s", p.source, dest))
@@ -117,10 +275,233 @@ func (p *DegitPlugin) Execute(ctx *sdk.Context) error {
 		return err
 	}
 
+	if p.graph {
+		if root := d.Graph(); root != nil {
+			printGraph(root, "", "")
+		}
+	}
+
 	sdk.Success(fmt.Sprintf("Cloned %s to %s", p.source, dest))
 	return nil
 }
 
+// projectDir returns the absolute project directory for update, diff and
+// validate
+func (p *DegitPlugin) projectDir(ctx *sdk.Context) string {
+	dest := p.dest
+	if dest == "" {
+		dest = "."
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(ctx.WorkingDir, dest)
+	}
+	return dest
+}
+
+// runUpdate merges template changes into a previously cloned project
+func (p *DegitPlugin) runUpdate(ctx *sdk.Context) error {
+	dest := p.projectDir(ctx)
+
+	d := degit.New(degit.Options{
+		Cache:          p.cache,
+		Verbose:        p.verbose,
+		Token:          auth.GitHubToken(),
+		CacheZstd:      p.zstd,
+		Version:        version,
+		NonInteractive: p.noInput,
+	})
+
+	result, err := d.Update(dest, p.ref)
+	if err != nil {
+		return err
+	}
+
+	if result.From == result.To {
+		sdk.Info(fmt.Sprintf("%s is already up to date", dest))
+		return nil
+	}
+
+	for _, e := range result.Entries {
+		fmt.Printf("  %-9s %s\n", e.Status, e.Path)
+	}
+
+	conflicts := result.Count(degit.UpdateConflict) + result.Count(degit.UpdateRejected) + result.Count(degit.UpdateKept)
+	if conflicts > 0 {
+		sdk.Warning(fmt.Sprintf("Updated %s to %s with %d file(s) needing attention", dest, result.To, conflicts))
+		return nil
+	}
+
+	sdk.Success(fmt.Sprintf("Updated %s to %s", dest, result.To))
+	return nil
+}
+
+// runDiff compares a previously cloned project with its template
+func (p *DegitPlugin) runDiff(ctx *sdk.Context) error {
+	dest := p.projectDir(ctx)
+
+	d := degit.New(degit.Options{
+		Cache:          p.cache,
+		Verbose:        p.verbose,
+		Token:          auth.GitHubToken(),
+		CacheZstd:      p.zstd,
+		NonInteractive: p.noInput,
+	})
+
+	result, err := d.Diff(dest, p.ref)
+	if err != nil {
+		return err
+	}
+
+	var added, removed []string
+	for _, e := range result.Entries {
+		switch e.Status {
+		case degit.DiffModified:
+			fmt.Print(e.Patch)
+		case degit.DiffAdded:
+			added = append(added, e.Path)
+		case degit.DiffRemoved:
+			removed = append(removed, e.Path)
+		}
+	}
+
+	if len(added) > 0 {
+		sdk.Info("Added (not in template):")
+		for _, path := range added {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+	if len(removed) > 0 {
+		sdk.Info("Removed (only in template):")
+		for _, path := range removed {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+
+	if len(result.Entries) == 0 {
+		sdk.Info(fmt.Sprintf("%s matches the template at %s", dest, result.Hash))
+	}
+	return nil
+}
+
+// runValidate checks a template's manifest, given as a file or the
+// template directory
+func (p *DegitPlugin) runValidate(ctx *sdk.Context) error {
+	path := p.projectDir(ctx)
+
+	if info, err := os.Stat(path); err != nil {
+		return err
+	} else if info.IsDir() {
+		if path, err = degit.FindManifest(path); err != nil {
+			return err
+		}
+	}
+
+	problems, err := degit.ValidateFile(path)
+	if err != nil {
+		return err
+	}
+
+	// Unknown fields are only warnings: clones ignore them
+	failures, warnings := 0, 0
+	for _, problem := range problems {
+		if problem.Warning {
+			warnings++
+			sdk.Warning(problem.Error())
+			continue
+		}
+		failures++
+		fmt.Println(problem.Error())
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d problem(s) found in %s", failures, path)
+	}
+
+	if warnings > 0 {
+		sdk.Success(fmt.Sprintf("%s is valid, with %d warning(s)", path, warnings))
+		return nil
+	}
+	sdk.Success(fmt.Sprintf("%s is valid", path))
+	return nil
+}
+
+// printPlan prints the files and actions a clone would produce
+func printPlan(plan *degit.Plan) {
+	sdk.Info(fmt.Sprintf("Dry run: %s (%s) -> %s", plan.Source, plan.Hash, plan.Dest))
+
+	for _, e := range plan.Entries {
+		fmt.Printf("  %-9s %s\n", e.Action, e.Path)
+	}
+
+	for _, v := range plan.Variables {
+		fmt.Printf("  variable  %s\n", v.Name)
+	}
+
+	for _, a := range plan.Actions {
+		desc := a.Action
+		switch a.Action {
+		case "clone":
+			desc += " " + a.Src
+			if a.Dest != "" {
+				desc += " -> " + a.Dest
+			}
+		case "remove":
+			desc += " " + strings.Join(a.Files, ", ")
+		case "rename", "move", "copy":
+			desc += " " + a.From + " -> " + a.To
+		case "replace":
+			desc += " " + a.Search + " -> " + a.Replace
+		case "patch":
+			desc += " " + a.Patch
+		case "run":
+			desc += " " + a.Command
+		case "prompt":
+			desc += " " + a.Name
+		}
+		if a.If != "" {
+			desc += " (if " + a.If + ")"
+		}
+		fmt.Printf("  action    %s\n", desc)
+	}
+
+	sdk.Info(fmt.Sprintf("%d to create, %d to overwrite, %d skipped, %d actions",
+		plan.Count(degit.PlanCreate), plan.Count(degit.PlanOverwrite),
+		plan.Count(degit.PlanSkip), len(plan.Actions)))
+}
+
+// printGraph prints the tree of sources composing a scaffold
+func printGraph(node *degit.SourceNode, prefix string, childPrefix string) {
+	desc := node.Source
+	if node.Ref != "" && node.Ref != "HEAD" {
+		desc += "#" + node.Ref
+	}
+	if node.Dest != "" && node.Dest != "." {
+		desc += " -> " + node.Dest
+	}
+	switch {
+	case node.Duplicate:
+		desc += " (already cloned)"
+	case node.Hash != "":
+		desc += " @ " + shortHash(node.Hash)
+	}
+	fmt.Printf("%s%s\n", prefix, desc)
+
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			printGraph(child, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			printGraph(child, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}
+
+// shortHash abbreviates a commit hash like git does
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
 // runInteractive shows a fuzzy-searchable list of cached repos
 func (p *DegitPlugin) runInteractive(ctx *sdk.Context) error {
 	selected, err := degit.RunInteractive()
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", value: "", want: nil},
+		{name: "empty list", value: "[]", want: nil},
+		{name: "single value", value: "docs", want: []string{"docs"}},
+		{name: "single value with commas", value: "*.{go,proto}", want: []string{"*.{go,proto}"}},
+		{name: "one item", value: "[docs]", want: []string{"docs"}},
+		{name: "several items", value: "[docs,.github,LICENSE]", want: []string{"docs", ".github", "LICENSE"}},
+		{name: "quoted glob with commas", value: `["*.{go,proto}",docs]`, want: []string{"*.{go,proto}", "docs"}},
+		{name: "glob with equals", value: "[a=b/*.txt,c]", want: []string{"a=b/*.txt", "c"}},
+		{name: "variable with commas", value: `["desc=a, b",name=x]`, want: []string{"desc=a, b", "name=x"}},
+		{name: "variable with quotes", value: `["msg=say ""hi"""]`, want: []string{`msg=say "hi"`}},
+		{name: "empty item", value: `["",a]`, want: []string{"", "a"}},
+		{name: "unterminated quote", value: `["a,b]`, wantErr: true},
+		{name: "stray quote", value: `[a"b]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitList("include", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitList(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitList(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -15,7 +15,7 @@ metadata:
 commands:
   - name: degit
     description: Clone a git repository without history
-    usage: ss degit <source> [dest] [flags]
+    usage: ss degit <source> [dest] [flags] | ss degit update|diff [dest] [flags] | ss degit validate [dir]
     flags:
       - name: force
         short: f
@@ -33,6 +33,51 @@ commands:
         short: v
         description: Enable verbose output
         type: bool
+      - name: cache-zstd
+        description: Store cached tarballs recompressed with zstd
+        type: bool
+      - name: include
+        description: Only extract files matching this glob (repeatable)
+        type: stringArray
+      - name: exclude
+        description: Skip files matching this glob (repeatable)
+        type: stringArray
+      - name: map
+        description: Extract a subdirectory to a path, as subdir:dest (repeatable)
+        type: stringArray
+      - name: var
+        description: Set a template variable, as key=value (repeatable)
+        type: stringArray
+      - name: answers
+        description: JSON file with answers to template prompts
+        type: string
+      - name: non-interactive
+        description: Never prompt; use answers, --var values and defaults
+        type: bool
+      - name: allow-scripts
+        description: Run commands of degit.json run actions from any template
+        type: bool
+      - name: show-graph
+        description: Print the tree of sources and hashes composing the scaffold
+        type: bool
+      - name: max-depth
+        description: How deeply degit.json clone actions may nest (default 10)
+        type: string
+      - name: conflict
+        description: How to handle existing files (overwrite, skip, error, backup or prompt)
+        type: string
+      - name: no-lock
+        description: Do not write .degit.lock into the destination
+        type: bool
+      - name: lock-format
+        description: Format of .degit.lock (json or yaml)
+        type: string
+      - name: ref
+        description: Template ref for update and diff (defaults to the recorded one)
+        type: string
+      - name: dry-run
+        description: Show files and actions without writing to the destination
+        type: bool
 
 # Runtime configuration with platform-specific binaries
 runtime: